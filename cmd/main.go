@@ -6,10 +6,16 @@ import (
     "os"
     "os/signal"
     "syscall"
+    "time"
 
     "github.com/sial-ari/solana-token-sniper/internal/config"
     "github.com/sial-ari/solana-token-sniper/internal/db"
     "github.com/sial-ari/solana-token-sniper/internal/jupiter"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/pubsub"
+    "github.com/sial-ari/solana-token-sniper/internal/pyth"
+    "github.com/sial-ari/solana-token-sniper/internal/raydium"
+    "github.com/sial-ari/solana-token-sniper/internal/strategy"
     "github.com/sial-ari/solana-token-sniper/internal/telegram"
     "github.com/sial-ari/solana-token-sniper/internal/websocket"
 )
@@ -20,45 +26,109 @@ func main() {
         log.Fatalf("Failed to load config: %v", err)
     }
 
+    appLogger, err := logger.NewLogger(cfg.LogPath, logger.Options{
+        Level:     logger.ParseLevel(cfg.LogLevel),
+        Format:    logger.ParseFormat(cfg.LogFormat),
+        MaxSizeMB: cfg.LogFileMaxMB,
+    })
+    if err != nil {
+        log.Fatalf("Failed to create logger: %v", err)
+    }
+    defer appLogger.Close()
+
+    ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), appLogger))
+    defer cancel()
+
     database, err := db.Initialize(cfg.DatabasePath)
     if err != nil {
-        log.Fatalf("Failed to initialize database: %v", err)
+        appLogger.Fatal("failed to initialize database", logger.F("error", err.Error()))
+        os.Exit(1)
     }
+    database.SetLogger(appLogger.Named("db"))
 
     jupiterClient, err := jupiter.NewClient(database, cfg.QueueSize, cfg.QuoteInterval)
     if err != nil {
-        log.Fatalf("Failed to create Jupiter client: %v", err)
+        appLogger.Fatal("failed to create Jupiter client", logger.F("error", err.Error()))
+        os.Exit(1)
     }
 
+    // Raydium pools go here as they're discovered, so brand-new pump.fun
+    // tokens can still be quoted once they graduate to a Raydium pool but
+    // before Jupiter's aggregator has indexed a route for them
+    jupiterClient.RegisterBackend(raydium.NewBackend(cfg.SolanaRPCURL))
+
     wsClient := websocket.NewClient(cfg.WebsocketURL, database, cfg.QueueSize)
 
+    bus, err := pubsub.NewBus(cfg.PubSubURL)
+    if err != nil {
+        appLogger.Fatal("failed to create pub/sub bus", logger.F("error", err.Error()))
+        os.Exit(1)
+    }
+    defer bus.Close()
+
+    wsClient.SetBus(bus)
+    jupiterClient.SetBus(bus)
+
     // Create and start Telegram bot
     bot, err := telegram.NewBot(cfg.TelegramToken, database, jupiterClient, cfg)
     if err != nil {
-        log.Fatalf("Failed to create Telegram bot: %v", err)
+        appLogger.Fatal("failed to create Telegram bot", logger.F("error", err.Error()))
+        os.Exit(1)
     }
 
-    ctx, cancel := context.WithCancel(context.Background())
-    defer cancel()
+    // Default exit rules: take half the position at +100%, the rest at
+    // +300%, hard stop at -30%, trail 20% off the running max, and bail
+    // if a token hasn't moved +20% within half an hour of entry
+    strategyConfig := strategy.Config{
+        Default: strategy.Rules{
+            TakeProfit: []strategy.TakeProfitLevel{
+                {TriggerPct: 100, SellPct: 0.5},
+                {TriggerPct: 300, SellPct: 1},
+            },
+            StopLossPct:     30,
+            TrailingStopPct: 20,
+            TimeExit:        &strategy.TimeExitRule{Window: 30 * time.Minute, MinGainPct: 20},
+        },
+    }
+    strategyEngine, err := strategy.NewEngine(database, jupiterClient, bus, strategyConfig, cfg.UserPublicKey, cfg.DryRun)
+    if err != nil {
+        appLogger.Fatal("failed to create strategy engine", logger.F("error", err.Error()))
+        os.Exit(1)
+    }
+    bot.SetStrategyEngine(strategyEngine)
+
+    pythClient := pyth.NewClient(cfg.PythWebsocketURL, database)
+    if err := pythClient.Connect(ctx); err != nil {
+        appLogger.Warn("failed to connect to Pyth WebSocket server", logger.F("error", err.Error()))
+    } else {
+        // Mint -> Pyth price account feeds go here as they're discovered;
+        // most freshly-minted pump.fun tokens won't have one
+        wsClient.SetPythClient(pythClient, map[string]string{})
+        defer pythClient.Close()
+    }
 
     // Start all services
     if err := wsClient.Connect(ctx); err != nil {
-        log.Fatalf("Failed to connect to WebSocket server: %v", err)
+        appLogger.Fatal("failed to connect to WebSocket server", logger.F("error", err.Error()))
+        os.Exit(1)
     }
 
     go jupiterClient.StartPriceMonitoring(ctx)
+    go strategyEngine.Start(ctx)
     go bot.Start(ctx)
+    go bot.StartNotifier(ctx, bus)
 
     // Handle shutdown
     sigChan := make(chan os.Signal, 1)
     signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
     <-sigChan
-    log.Println("Shutting down...")
-    
+    appLogger.Info("shutting down")
+
     // Graceful shutdown of all components
+    strategyEngine.Close()
     jupiterClient.Close()
     if err := wsClient.Close(); err != nil {
-        log.Printf("Error closing WebSocket connection: %v", err)
+        appLogger.Error("failed to close WebSocket connection", logger.F("error", err.Error()))
     }
 }
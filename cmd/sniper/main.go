@@ -3,57 +3,324 @@ package main
 
 import (
     "context"
+    "crypto/rand"
+    "encoding/base64"
     "flag"
+    "fmt"
     "os"
     "os/signal"
     "syscall"
+    "time"
 
-    "github.com/yourusername/solana-token-sniper/internal/scanner"
-    "github.com/yourusername/solana-token-sniper/internal/metrics"
-    "github.com/yourusername/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/backtest"
+    "github.com/sial-ari/solana-token-sniper/internal/config"
+    "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/identity"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/strategy"
+    "github.com/sial-ari/solana-token-sniper/internal/telegram"
+    "github.com/sial-ari/solana-token-sniper/internal/websocket"
+    "github.com/sial-ari/solana-token-sniper/internal/websocket/testvectors"
 )
 
 func main() {
+    // "gen" is a subcommand rather than a flag, since it doesn't share any
+    // of the flags below and is invoked before there's a config to load
+    if len(os.Args) > 1 && os.Args[1] == "gen" {
+        if err := runGen(os.Args[2:]); err != nil {
+            fmt.Fprintf(os.Stderr, "gen failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // "backtest" is likewise a subcommand: it replays a recorded database
+    // instead of starting the live pipeline
+    if len(os.Args) > 1 && os.Args[1] == "backtest" {
+        if err := runBacktest(os.Args[2:]); err != nil {
+            fmt.Fprintf(os.Stderr, "backtest failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
     // Parse command line flags
-    configPath := flag.String("config", "config.json", "Path to configuration file")
+    recordPath := flag.String("record", "", "Capture raw WebSocket frames into a testvectors corpus at this path")
+    replayPath := flag.String("replay", "", "Replay a testvectors corpus from this path against an in-memory DB instead of connecting live")
+    dbPath := flag.String("db", "tokens.db", "Path to the sqlite database, used by -migrate-only and -rollback")
+    migrateOnly := flag.Bool("migrate-only", false, "Apply pending schema migrations to -db then exit")
+    rollback := flag.Int("rollback", 0, "Roll back the last N applied schema migrations on -db then exit")
     flag.Parse()
 
-    // Initialize logger
-    log, err := logger.NewLogger("logs/sniper.log")
+    if *rollback > 0 {
+        if err := runRollback(*dbPath, *rollback); err != nil {
+            fmt.Fprintf(os.Stderr, "rollback failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *migrateOnly {
+        if err := runMigrateOnly(*dbPath); err != nil {
+            fmt.Fprintf(os.Stderr, "migrate failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *replayPath != "" {
+        if err := runReplay(*replayPath); err != nil {
+            fmt.Fprintf(os.Stderr, "replay failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *recordPath != "" {
+        if err := runRecord(*recordPath); err != nil {
+            fmt.Fprintf(os.Stderr, "record failed: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // None of -migrate-only, -rollback, -replay or -record were given, and
+    // neither was a gen/backtest subcommand: there's nothing left for this
+    // binary to do. The live trading pipeline lives in cmd/main.go, not
+    // here - this binary is the operator tool for bootstrapping config,
+    // backtesting, and working with recorded WebSocket corpora.
+    fmt.Fprintln(os.Stderr, "usage: sniper gen | sniper backtest | sniper -migrate-only | sniper -rollback N | sniper -replay path | sniper -record path")
+    fmt.Fprintln(os.Stderr, "for live trading, run the cmd/main.go binary instead")
+    os.Exit(1)
+}
+
+// runRecord connects to the configured WebSocket feed, appends every raw
+// frame it receives to a testvectors corpus, and processes it normally
+// against an on-disk database so recording doesn't change client behavior.
+func runRecord(corpusPath string) error {
+    recorder, closer, err := testvectors.NewRecorder(corpusPath)
     if err != nil {
-        panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+        return fmt.Errorf("failed to open corpus for recording: %w", err)
     }
-    defer log.Close()
+    defer closer.Close()
 
-    // Initialize metrics client
-    metrics, err := metrics.NewMetricsClient(
-        context.Background(),
-        os.Getenv("DATABASE_URL"),
-        log,
-    )
+    database, err := db.Initialize("tokens.db")
     if err != nil {
-        log.Error(fmt.Sprintf("Failed to initialize metrics client: %v", err))
-        os.Exit(1)
+        return fmt.Errorf("failed to initialize database: %w", err)
     }
-    defer metrics.Close()
 
-    // Initialize scanner
-    tokenScanner := scanner.NewScanner(metrics, log)
+    client := websocket.NewClient("wss://pumpportal.fun/api/data", database, 5)
+    client.SetFrameRecorder(recorder)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
 
-    // Start the scanner
-    if err := tokenScanner.Start(); err != nil {
-        log.Error(fmt.Sprintf("Failed to start scanner: %v", err))
-        os.Exit(1)
+    if err := client.Connect(ctx); err != nil {
+        return fmt.Errorf("failed to connect to WebSocket server: %w", err)
     }
 
-    // Handle shutdown signals
     sigChan := make(chan os.Signal, 1)
     signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-    // Wait for shutdown signal
     <-sigChan
-    log.Info("Shutdown signal received, stopping scanner...")
-    
-    // Graceful shutdown
-    tokenScanner.Stop()
+
+    return client.Close()
+}
+
+// runReplay feeds every frame in a testvectors corpus through
+// websocket.Client's decoding path against a fresh in-memory database, so
+// regressions in processMessage are caught without needing a live feed.
+func runReplay(corpusPath string) error {
+    database, err := db.Initialize(":memory:")
+    if err != nil {
+        return fmt.Errorf("failed to initialize in-memory database: %w", err)
+    }
+
+    client := websocket.NewClient("", database, 5)
+
+    errs, err := testvectors.Replay(corpusPath, client.ProcessRawMessage)
+    if err != nil {
+        return fmt.Errorf("failed to replay corpus: %w", err)
+    }
+
+    for _, replayErr := range errs {
+        fmt.Fprintf(os.Stderr, "frame %d: %v\n", replayErr.Index, replayErr.Err)
+    }
+
+    fmt.Printf("replayed %s: %d frame(s) failed to process\n", corpusPath, len(errs))
+    return nil
+}
+
+// runGen bootstraps a new serverConfig.json: a fresh Solana keypair for
+// signing swaps, a separate ed25519 identity the bot uses to authenticate
+// privileged commands, and an AllowedUsers list populated by waiting for a
+// one-time /register <code> message from each chat that should be trusted.
+func runGen(args []string) error {
+    fs := flag.NewFlagSet("gen", flag.ExitOnError)
+    out := fs.String("out", "serverConfig.json", "Path to write the generated server config")
+    telegramToken := fs.String("telegram-token", os.Getenv("TELEGRAM_TOKEN"), "Telegram bot token to run the /register handshake against")
+    timeout := fs.Duration("timeout", 5*time.Minute, "How long to wait for the /register handshake before giving up")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    cfg := config.Default()
+
+    wallet, err := identity.NewSolanaKeypair()
+    if err != nil {
+        return fmt.Errorf("failed to generate Solana keypair: %w", err)
+    }
+    cfg.UserPublicKey = wallet.PublicKey
+    cfg.UserPrivateKey = wallet.PrivateKey
+
+    signing, err := identity.NewSigningKeypair()
+    if err != nil {
+        return fmt.Errorf("failed to generate signing keypair: %w", err)
+    }
+    cfg.SigningPublicKey = signing.PublicKey
+    cfg.SigningPrivateKey = signing.PrivateKey
+
+    if *telegramToken == "" {
+        fmt.Println("No Telegram token provided; skipping the /register handshake. AllowedUsers will be empty until added by hand.")
+    } else {
+        code, err := registrationCode()
+        if err != nil {
+            return fmt.Errorf("failed to generate registration code: %w", err)
+        }
+
+        fmt.Printf("From every chat that should be allowed to run privileged commands, send:\n\n  /register %s\n\nWaiting up to %s (Ctrl+C to stop early)...\n", code, *timeout)
+
+        ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+        defer cancel()
+
+        sigChan := make(chan os.Signal, 1)
+        signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+        go func() {
+            <-sigChan
+            cancel()
+        }()
+
+        allowed, err := telegram.AwaitRegistrations(ctx, *telegramToken, code)
+        if err != nil {
+            return fmt.Errorf("registration handshake failed: %w", err)
+        }
+        cfg.AllowedUsers = allowed
+        fmt.Printf("registered %d chat(s)\n", len(allowed))
+    }
+
+    if err := cfg.Save(*out); err != nil {
+        return fmt.Errorf("failed to write server config: %w", err)
+    }
+
+    fmt.Printf("wrote %s (UserPublicKey: %s)\n", *out, cfg.UserPublicKey)
+    return nil
+}
+
+// runMigrateOnly applies every pending schema migration to dbPath and
+// exits, without starting the scanner - for provisioning or upgrading a
+// database ahead of a deploy.
+func runMigrateOnly(dbPath string) error {
+    if _, err := db.Initialize(dbPath); err != nil {
+        return fmt.Errorf("failed to apply migrations: %w", err)
+    }
+    fmt.Printf("migrations applied to %s\n", dbPath)
+    return nil
+}
+
+// runRollback reverts the last n applied schema migrations on dbPath and
+// exits, for undoing a bad migration during development.
+func runRollback(dbPath string, n int) error {
+    database, err := db.Initialize(dbPath)
+    if err != nil {
+        return fmt.Errorf("failed to open database: %w", err)
+    }
+    if err := database.Rollback(n); err != nil {
+        return fmt.Errorf("failed to roll back migrations: %w", err)
+    }
+    fmt.Printf("rolled back %d migration(s) on %s\n", n, dbPath)
+    return nil
+}
+
+// runBacktest replays a mint's recorded price history through the same
+// exit rules cmd/main.go configures for live trading, with a mocked
+// Trader instead of a real swap, so stop-loss/take-profit/trailing-stop
+// parameters can be tuned against historical data before risking mainnet
+// SOL.
+func runBacktest(args []string) error {
+    fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+    dbPath := fs.String("db", "tokens.db", "Path to the sqlite database holding price_history")
+    mint := fs.String("mint", "", "Mint to replay (required)")
+    solAmount := fs.Float64("sol", 1.0, "SOL spent on the simulated entry")
+    useCandles := fs.Bool("candles", false, "Replay aggregated candles instead of raw price_history ticks")
+    period := fs.String("period", "1m", "Candle period to replay when -candles is set")
+    format := fs.String("format", "json", "Output format for -out: json or csv")
+    out := fs.String("out", "", "Write the result to this path instead of stdout")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if *mint == "" {
+        return fmt.Errorf("-mint is required")
+    }
+
+    database, err := db.Initialize(*dbPath)
+    if err != nil {
+        return fmt.Errorf("failed to open database: %w", err)
+    }
+
+    cfg := backtest.Config{
+        Mint:           *mint,
+        EntrySolAmount: *solAmount,
+        Rules: strategy.Rules{
+            TakeProfit: []strategy.TakeProfitLevel{
+                {TriggerPct: 100, SellPct: 0.5},
+                {TriggerPct: 300, SellPct: 1},
+            },
+            StopLossPct:     30,
+            TrailingStopPct: 20,
+            TimeExit:        &strategy.TimeExitRule{Window: 30 * time.Minute, MinGainPct: 20},
+        },
+    }
+    if *useCandles {
+        cfg.Source = backtest.SourceCandles
+        cfg.Period = models.KlinePeriod(*period)
+    }
+
+    result, err := backtest.Run(database, cfg)
+    if err != nil {
+        return fmt.Errorf("backtest run failed: %w", err)
+    }
+
+    w := os.Stdout
+    if *out != "" {
+        f, err := os.Create(*out)
+        if err != nil {
+            return fmt.Errorf("failed to create output file: %w", err)
+        }
+        defer f.Close()
+        w = f
+    }
+
+    var writeErr error
+    if *format == "csv" {
+        writeErr = result.WriteTradesCSV(w)
+    } else {
+        writeErr = result.WriteJSON(w)
+    }
+    if writeErr != nil {
+        return fmt.Errorf("failed to write result: %w", writeErr)
+    }
+
+    fmt.Fprintf(os.Stderr, "total PnL: %.4f SOL | win rate: %.1f%% | max drawdown: %.1f%% | holding time: %s\n",
+        result.TotalPnLSol, result.WinRate*100, result.MaxDrawdownPct, result.HoldingTime)
+    return nil
+}
+
+// registrationCode returns a random token for the /register handshake,
+// printed to the operator's terminal so registering a chat proves control
+// of both the Telegram chat and the host `gen` is running on.
+func registrationCode() (string, error) {
+    raw := make([]byte, 9)
+    if _, err := rand.Read(raw); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(raw), nil
 }
@@ -0,0 +1,227 @@
+// Package backtest replays a mint's recorded price_history (or candles)
+// through a strategy.Engine with a mocked strategy.Trader, so stop-loss,
+// take-profit, and trailing-stop parameters can be tuned against real
+// historical data before risking mainnet SOL.
+package backtest
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/strategy"
+)
+
+// Source selects which table a run replays ticks from.
+type Source int
+
+const (
+    SourceTicks   Source = iota // raw price_history rows, finest granularity
+    SourceCandles               // candles for Period, using each candle's close
+)
+
+// Fees models the cost of a fill: a proportional slippage against the
+// tick price plus a flat (in SOL) network/priority fee, charged the same
+// way on every exit a real swap would pay them.
+type Fees struct {
+    SlippageBps int
+    FlatFeeSol  float64
+}
+
+func (f Fees) apply(solAmount float64) float64 {
+    fee := solAmount*float64(f.SlippageBps)/10000 + f.FlatFeeSol
+    if fee < 0 {
+        fee = 0
+    }
+    return fee
+}
+
+// Config controls one backtest run.
+type Config struct {
+    Mint           string
+    Source         Source
+    Period         models.KlinePeriod // only used when Source is SourceCandles
+    EntrySolAmount float64
+    Fees           Fees
+    Rules          strategy.Rules
+}
+
+// Trade is one fill the mock Trader recorded during a run - the entry buy
+// or one of a position's partial or full exits.
+type Trade struct {
+    Side      string    `json:"side"` // "buy" or "sell"
+    Price     float64   `json:"price"`
+    SolAmount float64   `json:"solAmount"` // before fees
+    Fee       float64   `json:"fee"`
+    PnL       float64   `json:"pnl"` // 0 for the entry buy
+    Timestamp time.Time `json:"timestamp"`
+}
+
+// Result is the outcome of one backtest run.
+type Result struct {
+    Mint           string        `json:"mint"`
+    TotalPnLSol    float64       `json:"totalPnlSol"`
+    WinRate        float64       `json:"winRate"` // fraction of exits that were profitable
+    MaxDrawdownPct float64       `json:"maxDrawdownPct"`
+    HoldingTime    time.Duration `json:"holdingTime"`
+    Trades         []Trade       `json:"trades"`
+}
+
+// mockTrader implements strategy.Trader by recording a Trade at the tick
+// price the harness is currently replaying, instead of submitting a real
+// swap - ExecuteSwap takes no price of its own, so the harness sets price
+// and timestamp immediately before driving the engine through that tick.
+type mockTrader struct {
+    entryPrice float64
+    fees       Fees
+    price      float64
+    timestamp  time.Time
+    trades     []Trade
+}
+
+func (m *mockTrader) ExecuteSwap(ctx context.Context, mint string, solAmount float64, userPubKey string) error {
+    fee := m.fees.apply(solAmount)
+    pnl := solAmount*(pctChange(m.entryPrice, m.price)/100) - fee
+    m.trades = append(m.trades, Trade{
+        Side:      "sell",
+        Price:     m.price,
+        SolAmount: solAmount,
+        Fee:       fee,
+        PnL:       pnl,
+        Timestamp: m.timestamp,
+    })
+    return nil
+}
+
+func pctChange(from, to float64) float64 {
+    if from == 0 {
+        return 0
+    }
+    return (to - from) / from * 100
+}
+
+type tick struct {
+    price     float64
+    timestamp time.Time
+}
+
+// Run replays cfg.Mint's history through a strategy.Engine configured with
+// cfg.Rules, opening a position with cfg.EntrySolAmount at the first tick
+// and evaluating every subsequent tick against it.
+func Run(sourceDB *db.Database, cfg Config) (*Result, error) {
+    ticks, err := loadTicks(sourceDB, cfg)
+    if err != nil {
+        return nil, err
+    }
+    if len(ticks) == 0 {
+        return nil, fmt.Errorf("backtest: no history for %s", cfg.Mint)
+    }
+
+    // Position bookkeeping for the run lives in a throwaway in-memory
+    // database so replaying a mint never touches the real positions table
+    // in sourceDB.
+    scratch, err := db.Initialize(":memory:")
+    if err != nil {
+        return nil, fmt.Errorf("failed to create scratch database: %w", err)
+    }
+
+    first := ticks[0]
+    trader := &mockTrader{entryPrice: first.price, fees: cfg.Fees}
+    strategyConfig := strategy.Config{Default: cfg.Rules}
+    engine, err := strategy.NewEngine(scratch, trader, nil, strategyConfig, "", false)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create strategy engine: %w", err)
+    }
+
+    if err := engine.Enter(cfg.Mint, first.price, cfg.EntrySolAmount, first.timestamp); err != nil {
+        return nil, fmt.Errorf("failed to open backtest position: %w", err)
+    }
+
+    peakEquity := cfg.EntrySolAmount
+    var maxDrawdownPct float64
+    last := first
+
+    for _, t := range ticks[1:] {
+        last = t
+        trader.price = t.price
+        trader.timestamp = t.timestamp
+        if err := engine.Evaluate(context.Background(), cfg.Mint, t.price, t.timestamp); err != nil {
+            return nil, fmt.Errorf("failed to evaluate tick: %w", err)
+        }
+
+        pos, err := scratch.GetPosition(cfg.Mint)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read position: %w", err)
+        }
+
+        unrealizedPL := pos.EntrySolAmount * pos.RemainingPct * (pctChange(pos.EntryPrice, t.price) / 100)
+        equity := cfg.EntrySolAmount + pos.RealizedPL + unrealizedPL
+        if equity > peakEquity {
+            peakEquity = equity
+        }
+        if peakEquity > 0 {
+            if drawdownPct := (peakEquity - equity) / peakEquity * 100; drawdownPct > maxDrawdownPct {
+                maxDrawdownPct = drawdownPct
+            }
+        }
+    }
+
+    pos, err := scratch.GetPosition(cfg.Mint)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read final position: %w", err)
+    }
+
+    holdingEnd := last.timestamp
+    if pos.Closed {
+        holdingEnd = pos.ClosedAt
+    }
+
+    result := &Result{
+        Mint:           cfg.Mint,
+        MaxDrawdownPct: maxDrawdownPct,
+        HoldingTime:    holdingEnd.Sub(pos.EntryTime),
+        Trades:         trader.trades,
+    }
+
+    var wins int
+    for _, trade := range trader.trades {
+        result.TotalPnLSol += trade.PnL
+        if trade.PnL > 0 {
+            wins++
+        }
+    }
+    if pos.RemainingPct > 0 {
+        result.TotalPnLSol += pos.EntrySolAmount * pos.RemainingPct * (pctChange(pos.EntryPrice, last.price) / 100)
+    }
+    if len(trader.trades) > 0 {
+        result.WinRate = float64(wins) / float64(len(trader.trades))
+    }
+
+    return result, nil
+}
+
+func loadTicks(sourceDB *db.Database, cfg Config) ([]tick, error) {
+    if cfg.Source == SourceCandles {
+        klines, err := sourceDB.GetKlines(cfg.Mint, cfg.Period, -1)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load candles: %w", err)
+        }
+        ticks := make([]tick, len(klines))
+        for i, j := 0, len(klines)-1; j >= 0; i, j = i+1, j-1 {
+            ticks[i] = tick{price: klines[j].Close, timestamp: klines[j].OpenTime}
+        }
+        return ticks, nil
+    }
+
+    prices, err := sourceDB.GetPriceHistory(cfg.Mint)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load price history: %w", err)
+    }
+    ticks := make([]tick, len(prices))
+    for i, j := 0, len(prices)-1; j >= 0; i, j = i+1, j-1 {
+        ticks[i] = tick{price: prices[j].Price, timestamp: prices[j].Timestamp}
+    }
+    return ticks, nil
+}
@@ -0,0 +1,41 @@
+package backtest
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "io"
+    "strconv"
+)
+
+// WriteJSON encodes the full Result, including every recorded trade, as
+// pretty-printed JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+    enc := json.NewEncoder(w)
+    enc.SetIndent("", "  ")
+    return enc.Encode(r)
+}
+
+// WriteTradesCSV writes one row per trade: side, price, SOL amount, fee,
+// PnL, and timestamp, for loading into a spreadsheet.
+func (r *Result) WriteTradesCSV(w io.Writer) error {
+    writer := csv.NewWriter(w)
+    defer writer.Flush()
+
+    if err := writer.Write([]string{"side", "price", "sol_amount", "fee", "pnl", "timestamp"}); err != nil {
+        return err
+    }
+    for _, t := range r.Trades {
+        row := []string{
+            t.Side,
+            strconv.FormatFloat(t.Price, 'f', -1, 64),
+            strconv.FormatFloat(t.SolAmount, 'f', -1, 64),
+            strconv.FormatFloat(t.Fee, 'f', -1, 64),
+            strconv.FormatFloat(t.PnL, 'f', -1, 64),
+            t.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+        }
+        if err := writer.Write(row); err != nil {
+            return err
+        }
+    }
+    return writer.Error()
+}
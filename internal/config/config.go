@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -13,23 +16,142 @@ type Config struct {
 	TelegramToken    string
 	SolanaRPCURL     string
 	DryRun           bool
+	PythWebsocketURL string
+	PythProgramID    string
+	PubSubURL        string
+	NotifyChatID     int64
+	LogPath          string
+	LogLevel         string
+	LogFormat        string
+	LogFileMaxMB     int
+
+	// UserPublicKey/UserPrivateKey are the Solana wallet keypair swaps are
+	// signed with; generated once by `sniper gen`.
+	UserPublicKey  string
+	UserPrivateKey string
+
+	// SigningPublicKey/SigningPrivateKey are a separate ed25519 identity
+	// the Telegram bot uses to authenticate privileged commands, so a
+	// leaked TelegramToken alone can't be used to issue them.
+	SigningPublicKey  string
+	SigningPrivateKey string
+
+	// AllowedUsers lists the Telegram chat/user IDs that may run
+	// privileged commands without a signature, populated by the
+	// `/register` handshake in `sniper gen`.
+	AllowedUsers []int64
+
+	// ConfigPath is the file this Config was loaded from via
+	// LoadFromFile, and the file Save writes back to by default. It's
+	// empty for a Config built from the environment by LoadConfig.
+	ConfigPath string `json:"-"`
 }
 
 func LoadConfig() (*Config, error) {
 	queueSize, _ := strconv.Atoi(getEnvWithDefault("QUEUE_SIZE", "5"))
 	quoteInterval, _ := strconv.Atoi(getEnvWithDefault("QUOTE_INTERVAL", "30"))
+	notifyChatID, _ := strconv.ParseInt(getEnvWithDefault("NOTIFY_CHAT_ID", "0"), 10, 64)
+	logFileMaxMB, _ := strconv.Atoi(getEnvWithDefault("LOG_FILE_MAX_MB", "50"))
 
 	return &Config{
-		WebsocketURL:  getEnvWithDefault("WEBSOCKET_URL", "wss://pumpportal.fun/api/data"),
-		QueueSize:     queueSize,
-		QuoteInterval: quoteInterval,
-		DatabasePath:  getEnvWithDefault("DATABASE_PATH", "tokens.db"),
-		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
-		SolanaRPCURL:  getEnvWithDefault("SOLANA_RPC_URL", "https://api.mainnet-beta.solana.com"),
-		DryRun:        os.Getenv("DRY_RUN") == "true",
+		WebsocketURL:      getEnvWithDefault("WEBSOCKET_URL", "wss://pumpportal.fun/api/data"),
+		QueueSize:         queueSize,
+		QuoteInterval:     quoteInterval,
+		DatabasePath:      getEnvWithDefault("DATABASE_PATH", "tokens.db"),
+		TelegramToken:     os.Getenv("TELEGRAM_TOKEN"),
+		SolanaRPCURL:      getEnvWithDefault("SOLANA_RPC_URL", "https://api.mainnet-beta.solana.com"),
+		DryRun:            os.Getenv("DRY_RUN") == "true",
+		PythWebsocketURL:  getEnvWithDefault("PYTH_WEBSOCKET_URL", "wss://api.mainnet-beta.solana.com"),
+		PythProgramID:     getEnvWithDefault("PYTH_PROGRAM_ID", "FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH"),
+		PubSubURL:         os.Getenv("PUBSUB_URL"),
+		NotifyChatID:      notifyChatID,
+		LogPath:           getEnvWithDefault("LOG_PATH", "sniper.log"),
+		LogLevel:          getEnvWithDefault("LOG_LEVEL", "info"),
+		LogFormat:         getEnvWithDefault("LOG_FORMAT", "logfmt"),
+		LogFileMaxMB:      logFileMaxMB,
+		UserPublicKey:     os.Getenv("USER_PUBLIC_KEY"),
+		UserPrivateKey:    os.Getenv("USER_PRIVATE_KEY"),
+		SigningPublicKey:  os.Getenv("SIGNING_PUBLIC_KEY"),
+		SigningPrivateKey: os.Getenv("SIGNING_PRIVATE_KEY"),
+		AllowedUsers:      parseAllowedUsers(os.Getenv("ALLOWED_USERS")),
 	}, nil
 }
 
+// Default returns a Config populated with the same fallback values
+// LoadConfig uses when no environment variable overrides a field, so
+// `sniper gen` can produce a usable serverConfig.json without requiring an
+// environment to generate one from.
+func Default() *Config {
+	return &Config{
+		WebsocketURL:     "wss://pumpportal.fun/api/data",
+		QueueSize:        5,
+		QuoteInterval:    30,
+		DatabasePath:     "tokens.db",
+		SolanaRPCURL:     "https://api.mainnet-beta.solana.com",
+		PythWebsocketURL: "wss://api.mainnet-beta.solana.com",
+		PythProgramID:    "FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH",
+		LogPath:          "sniper.log",
+		LogLevel:         "info",
+		LogFormat:        "logfmt",
+		LogFileMaxMB:     50,
+	}
+}
+
+// LoadFromFile reads a Config previously written by `sniper gen` (or
+// persisted later by Save) from path.
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	cfg.ConfigPath = path
+	return &cfg, nil
+}
+
+// Save persists c as JSON to path, or to c.ConfigPath if path is empty, so
+// changes made via /setconfig survive a restart. It's an error if neither
+// is set, since a Config built by LoadConfig has nowhere to write to.
+func (c *Config) Save(path string) error {
+	if path == "" {
+		path = c.ConfigPath
+	}
+	if path == "" {
+		return fmt.Errorf("config has no file path to save to")
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	c.ConfigPath = path
+	return nil
+}
+
+func parseAllowedUsers(value string) []int64 {
+	if value == "" {
+		return nil
+	}
+
+	var ids []int64
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func getEnvWithDefault(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
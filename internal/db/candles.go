@@ -0,0 +1,151 @@
+package db
+
+import (
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+)
+
+// applyTick folds one price tick into the candle covering timestamp for
+// mint and period, updating it in place rather than re-scanning
+// price_history - the cost of a SaveTokenPrice call stays O(1) per period
+// regardless of how much history a mint has accumulated.
+func (d *Database) applyTick(mint string, period models.KlinePeriod, price float64, timestamp time.Time) error {
+    openTime := period.OpenTime(timestamp)
+
+    return d.withRetry(func() error {
+        var high, low, volume float64
+        err := d.db.QueryRow(`
+        SELECT high, low, volume
+        FROM candles
+        WHERE mint = ? AND period = ? AND open_time = ?`,
+            mint, string(period), openTime,
+        ).Scan(&high, &low, &volume)
+
+        switch err {
+        case sql.ErrNoRows:
+            _, err = d.db.Exec(`
+            INSERT INTO candles (mint, period, open_time, open, high, low, close, volume)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+                mint, string(period), openTime, price, price, price, price, 1.0,
+            )
+            return err
+        case nil:
+            if price > high {
+                high = price
+            }
+            if price < low {
+                low = price
+            }
+            _, err = d.db.Exec(`
+            UPDATE candles
+            SET high = ?, low = ?, close = ?, volume = ?
+            WHERE mint = ? AND period = ? AND open_time = ?`,
+                high, low, price, volume+1, mint, string(period), openTime,
+            )
+            return err
+        default:
+            return err
+        }
+    })
+}
+
+// GetKlines returns up to limit candles for mint and period, most recent
+// first.
+func (d *Database) GetKlines(mint string, period models.KlinePeriod, limit int) ([]models.Kline, error) {
+    operation := d.logger.TimeOperation("GetKlines")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    rows, err := d.db.Query(`
+        SELECT open_time, open, high, low, close, volume
+        FROM candles
+        WHERE mint = ? AND period = ?
+        ORDER BY open_time DESC
+        LIMIT ?`,
+        mint, string(period), limit,
+    )
+    if err != nil {
+        d.logger.Error("failed to query klines", logger.F("mint", mint), logger.F("error", err.Error()))
+        return nil, err
+    }
+    defer rows.Close()
+
+    var klines []models.Kline
+    for rows.Next() {
+        k := models.Kline{Mint: mint, Period: period}
+        if err := rows.Scan(&k.OpenTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume); err != nil {
+            return nil, err
+        }
+        klines = append(klines, k)
+    }
+    return klines, nil
+}
+
+// candleCount returns the total number of rows in the candles table,
+// used by Initialize to decide whether a backfill is needed.
+func (d *Database) candleCount() (int, error) {
+    var count int
+    err := d.db.QueryRow(`SELECT COUNT(*) FROM candles`).Scan(&count)
+    return count, err
+}
+
+// BackfillKlines rebuilds the candles table from price_history for every
+// mint and period, replacing whatever it currently holds. It's meant to
+// be run once when the candles table is introduced on a database that
+// already has price history, not on every startup.
+func (d *Database) BackfillKlines() error {
+    mints, err := d.distinctPriceHistoryMints()
+    if err != nil {
+        return err
+    }
+
+    if _, err := d.db.Exec(`DELETE FROM candles`); err != nil {
+        return err
+    }
+
+    for _, mint := range mints {
+        prices, err := d.GetPriceHistory(mint)
+        if err != nil {
+            return fmt.Errorf("failed to read price history for %s: %w", mint, err)
+        }
+        // GetPriceHistory orders newest-first; aggregation needs oldest-first.
+        for i, j := 0, len(prices)-1; i < j; i, j = i+1, j-1 {
+            prices[i], prices[j] = prices[j], prices[i]
+        }
+
+        for _, p := range prices {
+            for _, period := range models.AllKlinePeriods {
+                if err := d.applyTick(mint, period, p.Price, p.Timestamp); err != nil {
+                    return fmt.Errorf("failed to backfill %s candle for %s: %w", period, mint, err)
+                }
+            }
+        }
+    }
+    return nil
+}
+
+func (d *Database) distinctPriceHistoryMints() ([]string, error) {
+    rows, err := d.db.Query(`SELECT DISTINCT mint FROM price_history`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var mints []string
+    for rows.Next() {
+        var mint string
+        if err := rows.Scan(&mint); err != nil {
+            return nil, err
+        }
+        mints = append(mints, mint)
+    }
+    return mints, nil
+}
@@ -1,112 +1,362 @@
 package db
 
 import (
+    "context"
     "database/sql"
+    "errors"
+    "fmt"
+    "math/rand"
+    "sync/atomic"
     "time"
-    _ "github.com/mattn/go-sqlite3"
+
+    "github.com/mattn/go-sqlite3"
+    "golang.org/x/sync/semaphore"
+
+    "github.com/sial-ari/solana-token-sniper/internal/db/migrations"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
     "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/performance"
 )
 
+// ErrQueryOverloaded is returned by the read-path methods when the read
+// semaphore can't be acquired before readAcquireTimeout elapses, signaling
+// that a backlog of slow queries has built up rather than returning stale
+// or partial data.
+var ErrQueryOverloaded = errors.New("db: too many concurrent reads, try again later")
+
+// readAcquireTimeout bounds how long a read waits for a semaphore slot.
+// Read methods don't take a context (matching the rest of this package's
+// API), so this is a fixed budget rather than caller-supplied.
+const readAcquireTimeout = 5 * time.Second
+
+// Config tunes the connection pool and the bounded retry Initialize uses
+// to verify connectivity and subsequent writes use to ride out transient
+// SQLITE_BUSY/SQLITE_LOCKED errors.
+type Config struct {
+    MaxOpenConns       int
+    MaxIdleConns       int
+    ConnMaxLifetime    time.Duration
+    RetryAttempts      int           // bounded retries for the initial ping and for retryable Exec/Query errors
+    RetryBaseDelay     time.Duration // full-jitter exponential backoff base
+    RetryMaxDelay      time.Duration // backoff cap
+    MaxConcurrentReads int64         // weighted semaphore size guarding the read-path methods
+}
+
+// DefaultConfig is tuned for a single sniper instance writing to a local
+// SQLite file: a handful of pooled connections and a brief bounded retry,
+// since SQLITE_BUSY under WAL mode normally clears within milliseconds.
+func DefaultConfig() Config {
+    return Config{
+        MaxOpenConns:       10,
+        MaxIdleConns:       5,
+        ConnMaxLifetime:    time.Hour,
+        RetryAttempts:      5,
+        RetryBaseDelay:     50 * time.Millisecond,
+        RetryMaxDelay:      2 * time.Second,
+        MaxConcurrentReads: 8,
+    }
+}
+
 type Database struct {
-    db *sql.DB
+    db     *sql.DB
+    logger *logger.Logger
+    cfg    Config
+
+    readSem       *semaphore.Weighted
+    inFlightReads atomic.Int64
+    monitor       *performance.Monitor // optional; set via SetMonitor
+}
+
+// SetMonitor wires m so read-path wait time and in-flight count are
+// recorded against performance.OpDatabaseRead and the
+// "sniper_db_inflight_reads" gauge. Safe to skip; reads work unmonitored
+// if it's never called.
+func (d *Database) SetMonitor(m *performance.Monitor) {
+    d.monitor = m
+}
+
+// SetLogger switches d onto l, so callers constructed before a real
+// logger exists (e.g. during early startup) can upgrade off the no-op
+// default once one is available.
+func (d *Database) SetLogger(l *logger.Logger) {
+    d.logger = l
 }
 
-// Initialize creates a new database connection and sets up the schema
+// Initialize creates a new database connection, applies every pending
+// schema migration, and tunes the connection pool and WAL settings for
+// concurrent readers. Existing callers keep working unchanged; use
+// InitializeWithConfig to override the defaults.
 func Initialize(dbPath string) (*Database, error) {
-    db, err := sql.Open("sqlite3", dbPath)
+    return InitializeWithConfig(dbPath, DefaultConfig())
+}
+
+// InitializeWithConfig is Initialize with an explicit Config, for callers
+// that want a different pool size or retry budget than DefaultConfig.
+func InitializeWithConfig(dbPath string, cfg Config) (*Database, error) {
+    sqldb, err := sql.Open("sqlite3", dbPath)
     if err != nil {
         return nil, err
     }
+    sqldb.SetMaxOpenConns(cfg.MaxOpenConns)
+    sqldb.SetMaxIdleConns(cfg.MaxIdleConns)
+    sqldb.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
-    // Create tables if they don't exist
-    _, err = db.Exec(`
-        CREATE TABLE IF NOT EXISTS tokens (
-            mint TEXT PRIMARY KEY,
-            name TEXT,
-            symbol TEXT,
-            bonding_curve_key TEXT,
-            initial_buy REAL,
-            market_cap_sol REAL,
-            signature TEXT,
-            sol_amount REAL,
-            trader_public_key TEXT,
-            tx_type TEXT,
-            uri TEXT,
-            v_sol_in_bonding_curve REAL,
-            v_tokens_in_bonding_curve REAL,
-            created_at DATETIME
-        );
-
-        CREATE TABLE IF NOT EXISTS price_history (
-            mint TEXT,
-            price REAL,
-            timestamp DATETIME,
-            PRIMARY KEY (mint, timestamp),
-            FOREIGN KEY (mint) REFERENCES tokens(mint)
-        );
-
-        CREATE TABLE IF NOT EXISTS profit_loss (
-            mint TEXT PRIMARY KEY,
-            initial_price REAL,
-            current_price REAL,
-            profit_loss REAL,
-            profit_loss_pct REAL,
-            last_updated DATETIME,
-            FOREIGN KEY (mint) REFERENCES tokens(mint)
-        );
-    `)
+    if err := pingWithRetry(sqldb, cfg); err != nil {
+        return nil, fmt.Errorf("failed to connect to database: %w", err)
+    }
 
+    if _, err := sqldb.Exec(`PRAGMA journal_mode = WAL`); err != nil {
+        return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+    }
+    if _, err := sqldb.Exec(`PRAGMA busy_timeout = 5000`); err != nil {
+        return nil, fmt.Errorf("failed to set busy_timeout: %w", err)
+    }
+
+    if err := migrations.Migrate(sqldb); err != nil {
+        return nil, fmt.Errorf("failed to apply migrations: %w", err)
+    }
+
+    database := &Database{
+        db:      sqldb,
+        logger:  logger.FromContext(context.Background()),
+        cfg:     cfg,
+        readSem: semaphore.NewWeighted(cfg.MaxConcurrentReads),
+    }
+
+    candleCount, err := database.candleCount()
     if err != nil {
         return nil, err
     }
+    if candleCount == 0 {
+        // Fresh candles table on a database that may already have price
+        // history: rebuild candles for every mint seen so far instead of
+        // only aggregating ticks from this point forward.
+        if err := database.BackfillKlines(); err != nil {
+            return nil, err
+        }
+    }
+
+    return database, nil
+}
 
-    return &Database{db: db}, nil
+// pingWithRetry pings sqldb up to cfg.RetryAttempts times, full-jitter
+// backing off between attempts.
+func pingWithRetry(sqldb *sql.DB, cfg Config) error {
+    var lastErr error
+    for attempt := 1; attempt <= cfg.RetryAttempts; attempt++ {
+        if err := sqldb.Ping(); err == nil {
+            return nil
+        } else {
+            lastErr = err
+        }
+        time.Sleep(backoffDelay(attempt, cfg.RetryBaseDelay, cfg.RetryMaxDelay))
+    }
+    return fmt.Errorf("no response after %d attempts: %w", cfg.RetryAttempts, lastErr)
+}
+
+// backoffDelay returns a full-jitter exponential backoff for the given
+// attempt (1-indexed): a uniformly random duration between 0 and
+// min(max, base*2^(attempt-1)).
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+    cap := base * time.Duration(uint64(1)<<uint(attempt-1))
+    if cap <= 0 || cap > max {
+        cap = max
+    }
+    return time.Duration(rand.Int63n(int64(cap)))
+}
+
+// isRetryable reports whether err is SQLite's SQLITE_BUSY or
+// SQLITE_LOCKED, both of which a concurrent writer can expect to clear on
+// its own under WAL mode, as opposed to a permanent error like a
+// constraint violation or a malformed query.
+func isRetryable(err error) bool {
+    var sqliteErr sqlite3.Error
+    if errors.As(err, &sqliteErr) {
+        return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+    }
+    return false
+}
+
+// withRetry runs fn, retrying up to d.cfg.RetryAttempts times with
+// full-jitter backoff when it returns a retryable error, and returning
+// immediately on any other error.
+func (d *Database) withRetry(fn func() error) error {
+    var lastErr error
+    for attempt := 1; attempt <= d.cfg.RetryAttempts; attempt++ {
+        err := fn()
+        if err == nil {
+            return nil
+        }
+        if !isRetryable(err) {
+            return err
+        }
+        lastErr = err
+        time.Sleep(backoffDelay(attempt, d.cfg.RetryBaseDelay, d.cfg.RetryMaxDelay))
+    }
+    return lastErr
+}
+
+// acquireRead acquires a slot on the read semaphore, bounded by
+// readAcquireTimeout, and records the wait as performance.OpDatabaseRead
+// plus the resulting in-flight count as a gauge on d.monitor, if one is
+// set. Returns ErrQueryOverloaded if the timeout expires first.
+func (d *Database) acquireRead() error {
+    ctx, cancel := context.WithTimeout(context.Background(), readAcquireTimeout)
+    defer cancel()
+
+    start := time.Now()
+    err := d.readSem.Acquire(ctx, 1)
+    wait := time.Since(start)
+
+    if d.monitor != nil {
+        d.monitor.RecordMetric(performance.OpDatabaseRead, wait, err == nil)
+    }
+    if err != nil {
+        return ErrQueryOverloaded
+    }
+
+    n := d.inFlightReads.Add(1)
+    if d.monitor != nil {
+        d.monitor.SetGauge("sniper_db_inflight_reads", float64(n))
+    }
+    return nil
+}
+
+func (d *Database) releaseRead() {
+    d.readSem.Release(1)
+    n := d.inFlightReads.Add(-1)
+    if d.monitor != nil {
+        d.monitor.SetGauge("sniper_db_inflight_reads", float64(n))
+    }
+}
+
+// Rollback reverts the last n applied schema migrations, for undoing a
+// bad migration during development.
+func (d *Database) Rollback(n int) error {
+    return migrations.Rollback(d.db, n)
+}
+
+// Close releases the underlying connection.
+func (d *Database) Close() error {
+    return d.db.Close()
 }
 
 // SaveNewToken stores a new token in the database
 func (d *Database) SaveNewToken(token *models.NewToken) error {
-    _, err := d.db.Exec(`
+    operation := d.logger.TimeOperation("SaveNewToken")
+    defer operation.End()
+
+    err := d.withRetry(func() error {
+        _, err := d.db.Exec(`
         INSERT INTO tokens (
-            mint, name, symbol, bonding_curve_key, initial_buy, 
+            mint, name, symbol, bonding_curve_key, initial_buy,
             market_cap_sol, signature, sol_amount, trader_public_key,
             tx_type, uri, v_sol_in_bonding_curve, v_tokens_in_bonding_curve,
             created_at
         ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-        token.Mint, token.Name, token.Symbol, token.BondingCurveKey,
-        token.InitialBuy, token.MarketCapSol, token.Signature,
-        token.SolAmount, token.TraderPublicKey, token.TxType,
-        token.URI, token.VSolInBondingCurve, token.VTokensInBondingCurve,
-        time.Now(),
-    )
+            token.Mint, token.Name, token.Symbol, token.BondingCurveKey,
+            token.InitialBuy, token.MarketCapSol, token.Signature,
+            token.SolAmount, token.TraderPublicKey, token.TxType,
+            token.URI, token.VSolInBondingCurve, token.VTokensInBondingCurve,
+            time.Now(),
+        )
+        return err
+    })
+    if err != nil {
+        d.logger.Error("failed to save new token", logger.F("mint", token.Mint), logger.F("error", err.Error()))
+    }
     return err
 }
 
-// SaveTokenPrice stores a new price point for a token
+// SaveTokenPrice stores a new price point for a token and folds it into
+// the currently-open candle for every period in models.AllKlinePeriods
 func (d *Database) SaveTokenPrice(price *models.TokenPrice) error {
-    _, err := d.db.Exec(`
+    operation := d.logger.TimeOperation("SaveTokenPrice")
+    defer operation.End()
+
+    err := d.withRetry(func() error {
+        _, err := d.db.Exec(`
         INSERT INTO price_history (mint, price, timestamp)
         VALUES (?, ?, ?)`,
-        price.Mint, price.Price, price.Timestamp,
-    )
-    return err
+            price.Mint, price.Price, price.Timestamp,
+        )
+        return err
+    })
+    if err != nil {
+        d.logger.Error("failed to save token price", logger.F("mint", price.Mint), logger.F("error", err.Error()))
+        return err
+    }
+
+    for _, period := range models.AllKlinePeriods {
+        if err := d.applyTick(price.Mint, period, price.Price, price.Timestamp); err != nil {
+            return err
+        }
+    }
+    return nil
 }
 
 // UpdateProfitLoss updates the profit/loss calculation for a token
 func (d *Database) UpdateProfitLoss(pl *models.TokenProfitLoss) error {
-    _, err := d.db.Exec(`
+    operation := d.logger.TimeOperation("UpdateProfitLoss")
+    defer operation.End()
+
+    err := d.withRetry(func() error {
+        _, err := d.db.Exec(`
         INSERT OR REPLACE INTO profit_loss (
             mint, initial_price, current_price, profit_loss,
             profit_loss_pct, last_updated
         ) VALUES (?, ?, ?, ?, ?, ?)`,
-        pl.Mint, pl.InitialPrice, pl.CurrentPrice,
-        pl.ProfitLoss, pl.ProfitLossPct, pl.LastUpdated,
-    )
+            pl.Mint, pl.InitialPrice, pl.CurrentPrice,
+            pl.ProfitLoss, pl.ProfitLossPct, pl.LastUpdated,
+        )
+        return err
+    })
+    if err != nil {
+        d.logger.Error("failed to update profit/loss", logger.F("mint", pl.Mint), logger.F("error", err.Error()))
+    }
     return err
 }
 
+// GetProfitLoss retrieves the most recently recorded profit/loss for a
+// token, if UpdateProfitLoss has ever been called for it.
+func (d *Database) GetProfitLoss(mint string) (*models.TokenProfitLoss, error) {
+    operation := d.logger.TimeOperation("GetProfitLoss")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    pl := models.TokenProfitLoss{Mint: mint}
+    err := d.withRetry(func() error {
+        return d.db.QueryRow(`
+        SELECT initial_price, current_price, profit_loss, profit_loss_pct, last_updated
+        FROM profit_loss
+        WHERE mint = ?`,
+            mint,
+        ).Scan(&pl.InitialPrice, &pl.CurrentPrice, &pl.ProfitLoss, &pl.ProfitLossPct, &pl.LastUpdated)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &pl, nil
+}
+
 // GetTokensInQueue retrieves the most recent tokens up to the queue size
 func (d *Database) GetTokensInQueue(queueSize int) ([]models.NewToken, error) {
-    rows, err := d.db.Query(`
+    operation := d.logger.TimeOperation("GetTokensInQueue")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    var rows *sql.Rows
+    err := d.withRetry(func() error {
+        var queryErr error
+        rows, queryErr = d.db.Query(`
         SELECT mint, name, symbol, bonding_curve_key, initial_buy,
                market_cap_sol, signature, sol_amount, trader_public_key,
                tx_type, uri, v_sol_in_bonding_curve, v_tokens_in_bonding_curve,
@@ -114,9 +364,12 @@ func (d *Database) GetTokensInQueue(queueSize int) ([]models.NewToken, error) {
         FROM tokens
         ORDER BY created_at DESC
         LIMIT ?`,
-        queueSize,
-    )
+            queueSize,
+        )
+        return queryErr
+    })
     if err != nil {
+        d.logger.Error("failed to query tokens in queue", logger.F("error", err.Error()))
         return nil, err
     }
     defer rows.Close()
@@ -139,16 +392,114 @@ func (d *Database) GetTokensInQueue(queueSize int) ([]models.NewToken, error) {
     return tokens, nil
 }
 
+// SaveOraclePrice stores the latest oracle-reported price for a token,
+// replacing any previous reading
+func (d *Database) SaveOraclePrice(price *models.OraclePrice) error {
+    operation := d.logger.TimeOperation("SaveOraclePrice")
+    defer operation.End()
+
+    err := d.withRetry(func() error {
+        _, err := d.db.Exec(`
+        INSERT OR REPLACE INTO oracle_prices (
+            mint, price, confidence, expo, publish_slot, source, timestamp
+        ) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+            price.Mint, price.Price, price.Confidence, price.Expo,
+            price.PublishSlot, price.Source, price.Timestamp,
+        )
+        return err
+    })
+    if err != nil {
+        d.logger.Error("failed to save oracle price", logger.F("mint", price.Mint), logger.F("error", err.Error()))
+    }
+    return err
+}
+
+// GetOraclePrice retrieves the latest oracle price for a token, if any
+func (d *Database) GetOraclePrice(mint string) (*models.OraclePrice, error) {
+    operation := d.logger.TimeOperation("GetOraclePrice")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    var p models.OraclePrice
+    p.Mint = mint
+    err := d.withRetry(func() error {
+        return d.db.QueryRow(`
+        SELECT price, confidence, expo, publish_slot, source, timestamp
+        FROM oracle_prices
+        WHERE mint = ?`,
+            mint,
+        ).Scan(&p.Price, &p.Confidence, &p.Expo, &p.PublishSlot, &p.Source, &p.Timestamp)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+// GetTokenBySymbol looks up the most recently seen token with the given symbol
+func (d *Database) GetTokenBySymbol(symbol string) (*models.NewToken, error) {
+    operation := d.logger.TimeOperation("GetTokenBySymbol")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    var t models.NewToken
+    err := d.withRetry(func() error {
+        return d.db.QueryRow(`
+        SELECT mint, name, symbol, bonding_curve_key, initial_buy,
+               market_cap_sol, signature, sol_amount, trader_public_key,
+               tx_type, uri, v_sol_in_bonding_curve, v_tokens_in_bonding_curve,
+               created_at
+        FROM tokens
+        WHERE symbol = ?
+        ORDER BY created_at DESC
+        LIMIT 1`,
+            symbol,
+        ).Scan(
+            &t.Mint, &t.Name, &t.Symbol, &t.BondingCurveKey,
+            &t.InitialBuy, &t.MarketCapSol, &t.Signature,
+            &t.SolAmount, &t.TraderPublicKey, &t.TxType,
+            &t.URI, &t.VSolInBondingCurve, &t.VTokensInBondingCurve,
+            &t.CreatedAt,
+        )
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
+
 // GetPriceHistory retrieves the price history for a specific token
 func (d *Database) GetPriceHistory(mint string) ([]models.TokenPrice, error) {
-    rows, err := d.db.Query(`
+    operation := d.logger.TimeOperation("GetPriceHistory")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    var rows *sql.Rows
+    err := d.withRetry(func() error {
+        var queryErr error
+        rows, queryErr = d.db.Query(`
         SELECT price, timestamp
         FROM price_history
         WHERE mint = ?
         ORDER BY timestamp DESC`,
-        mint,
-    )
+            mint,
+        )
+        return queryErr
+    })
     if err != nil {
+        d.logger.Error("failed to query price history", logger.F("mint", mint), logger.F("error", err.Error()))
         return nil, err
     }
     defer rows.Close()
@@ -0,0 +1,238 @@
+// Package migrations applies numbered, embedded SQL files against the
+// sqlite schema, tracking applied versions in a schema_migrations table so
+// db.Initialize can evolve the schema without ad-hoc column surgery on
+// every user's existing database.
+package migrations
+
+import (
+    "database/sql"
+    "embed"
+    "fmt"
+    "io/fs"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+type migration struct {
+    version int
+    name    string
+    up      string
+    down    string
+}
+
+// Migrate applies every embedded migration newer than the highest version
+// recorded in schema_migrations, in order, each inside its own
+// transaction.
+func Migrate(db *sql.DB) error {
+    migrations, err := load()
+    if err != nil {
+        return err
+    }
+
+    if err := ensureSchemaMigrationsTable(db); err != nil {
+        return err
+    }
+
+    applied, err := appliedVersions(db)
+    if err != nil {
+        return err
+    }
+
+    for _, m := range migrations {
+        if applied[m.version] {
+            continue
+        }
+        if err := apply(db, m); err != nil {
+            return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+        }
+    }
+    return nil
+}
+
+// Rollback reverts the last n applied migrations, most recently applied
+// first, using each migration's down script.
+func Rollback(db *sql.DB, n int) error {
+    migrations, err := load()
+    if err != nil {
+        return err
+    }
+    byVersion := make(map[int]migration, len(migrations))
+    for _, m := range migrations {
+        byVersion[m.version] = m
+    }
+
+    versions, err := appliedVersionsDesc(db)
+    if err != nil {
+        return err
+    }
+    if n > len(versions) {
+        n = len(versions)
+    }
+
+    for _, version := range versions[:n] {
+        m, ok := byVersion[version]
+        if !ok {
+            return fmt.Errorf("no migration source found for applied version %d", version)
+        }
+        if err := revert(db, m); err != nil {
+            return fmt.Errorf("rollback %04d_%s: %w", m.version, m.name, err)
+        }
+    }
+    return nil
+}
+
+// load reads every embedded sql/*.sql file and pairs up.sql/down.sql
+// files sharing a version into a migration, ordered ascending by version.
+func load() ([]migration, error) {
+    entries, err := fs.ReadDir(sqlFiles, "sql")
+    if err != nil {
+        return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+    }
+
+    byVersion := make(map[int]*migration)
+    for _, entry := range entries {
+        name := entry.Name()
+        version, rest, direction, err := parseFilename(name)
+        if err != nil {
+            return nil, err
+        }
+
+        contents, err := sqlFiles.ReadFile("sql/" + name)
+        if err != nil {
+            return nil, err
+        }
+
+        m, ok := byVersion[version]
+        if !ok {
+            m = &migration{version: version, name: rest}
+            byVersion[version] = m
+        }
+        switch direction {
+        case "up":
+            m.up = string(contents)
+        case "down":
+            m.down = string(contents)
+        }
+    }
+
+    migrations := make([]migration, 0, len(byVersion))
+    for _, m := range byVersion {
+        migrations = append(migrations, *m)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+    return migrations, nil
+}
+
+// parseFilename splits "0002_candles.up.sql" into version 2, name
+// "candles", direction "up".
+func parseFilename(name string) (version int, rest, direction string, err error) {
+    base := strings.TrimSuffix(name, ".sql")
+    switch {
+    case strings.HasSuffix(base, ".up"):
+        base = strings.TrimSuffix(base, ".up")
+        direction = "up"
+    case strings.HasSuffix(base, ".down"):
+        base = strings.TrimSuffix(base, ".down")
+        direction = "down"
+    default:
+        return 0, "", "", fmt.Errorf("migration %q missing .up/.down suffix", name)
+    }
+
+    parts := strings.SplitN(base, "_", 2)
+    if len(parts) != 2 {
+        return 0, "", "", fmt.Errorf("migration %q missing version prefix", name)
+    }
+    version, err = strconv.Atoi(parts[0])
+    if err != nil {
+        return 0, "", "", fmt.Errorf("migration %q has a non-numeric version: %w", name, err)
+    }
+    return version, parts[1], direction, nil
+}
+
+func ensureSchemaMigrationsTable(db *sql.DB) error {
+    _, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT,
+            applied_at DATETIME
+        )`)
+    return err
+}
+
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+    rows, err := db.Query(`SELECT version FROM schema_migrations`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    applied := make(map[int]bool)
+    for rows.Next() {
+        var version int
+        if err := rows.Scan(&version); err != nil {
+            return nil, err
+        }
+        applied[version] = true
+    }
+    return applied, nil
+}
+
+func appliedVersionsDesc(db *sql.DB) ([]int, error) {
+    rows, err := db.Query(`SELECT version FROM schema_migrations ORDER BY version DESC`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var versions []int
+    for rows.Next() {
+        var version int
+        if err := rows.Scan(&version); err != nil {
+            return nil, err
+        }
+        versions = append(versions, version)
+    }
+    return versions, nil
+}
+
+func apply(db *sql.DB, m migration) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec(m.up); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+        m.version, m.name, time.Now()); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func revert(db *sql.DB, m migration) error {
+    if m.down == "" {
+        return fmt.Errorf("migration %04d_%s has no down script", m.version, m.name)
+    }
+
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec(m.down); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.version); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
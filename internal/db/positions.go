@@ -0,0 +1,94 @@
+package db
+
+import (
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+)
+
+// SavePosition upserts pos, keyed by mint - the strategy engine calls
+// this after every entry and exit so a restart resumes from the same
+// state instead of losing track of an open position.
+func (d *Database) SavePosition(pos *models.Position) error {
+    operation := d.logger.TimeOperation("SavePosition")
+    defer operation.End()
+
+    err := d.withRetry(func() error {
+        _, err := d.db.Exec(`
+        INSERT OR REPLACE INTO positions (
+            mint, entry_price, entry_sol_amount, entry_time, max_price,
+            remaining_pct, take_profits_fired, realized_pl, closed, closed_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+            pos.Mint, pos.EntryPrice, pos.EntrySolAmount, pos.EntryTime, pos.MaxPrice,
+            pos.RemainingPct, pos.TakeProfitsFired, pos.RealizedPL, pos.Closed, pos.ClosedAt,
+        )
+        return err
+    })
+    if err != nil {
+        d.logger.Error("failed to save position", logger.F("mint", pos.Mint), logger.F("error", err.Error()))
+    }
+    return err
+}
+
+// GetOpenPositions returns every position not yet fully exited, for the
+// strategy engine to resume managing on startup.
+func (d *Database) GetOpenPositions() ([]models.Position, error) {
+    operation := d.logger.TimeOperation("GetOpenPositions")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    rows, err := d.db.Query(`
+        SELECT mint, entry_price, entry_sol_amount, entry_time, max_price,
+               remaining_pct, take_profits_fired, realized_pl, closed, closed_at
+        FROM positions
+        WHERE closed = 0`,
+    )
+    if err != nil {
+        d.logger.Error("failed to query open positions", logger.F("error", err.Error()))
+        return nil, err
+    }
+    defer rows.Close()
+
+    var positions []models.Position
+    for rows.Next() {
+        var p models.Position
+        if err := rows.Scan(
+            &p.Mint, &p.EntryPrice, &p.EntrySolAmount, &p.EntryTime, &p.MaxPrice,
+            &p.RemainingPct, &p.TakeProfitsFired, &p.RealizedPL, &p.Closed, &p.ClosedAt,
+        ); err != nil {
+            return nil, err
+        }
+        positions = append(positions, p)
+    }
+    return positions, nil
+}
+
+// GetPosition returns mint's position, if one has ever been opened.
+func (d *Database) GetPosition(mint string) (*models.Position, error) {
+    operation := d.logger.TimeOperation("GetPosition")
+    defer operation.End()
+
+    if err := d.acquireRead(); err != nil {
+        return nil, err
+    }
+    defer d.releaseRead()
+
+    p := models.Position{Mint: mint}
+    err := d.db.QueryRow(`
+        SELECT entry_price, entry_sol_amount, entry_time, max_price,
+               remaining_pct, take_profits_fired, realized_pl, closed, closed_at
+        FROM positions
+        WHERE mint = ?`,
+        mint,
+    ).Scan(
+        &p.EntryPrice, &p.EntrySolAmount, &p.EntryTime, &p.MaxPrice,
+        &p.RemainingPct, &p.TakeProfitsFired, &p.RealizedPL, &p.Closed, &p.ClosedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
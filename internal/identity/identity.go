@@ -0,0 +1,76 @@
+// Package identity generates the ed25519 keypairs used to bootstrap a
+// sniper instance: a Solana wallet keypair for signing swaps, and a
+// separate signing identity the Telegram bot uses to authenticate
+// privileged commands.
+package identity
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "encoding/base64"
+    "fmt"
+    "math/big"
+    "strings"
+)
+
+// base58Alphabet is the Bitcoin/Solana alphabet: it drops 0, O, I, and l
+// to avoid visual ambiguity when an address is read off a screen.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeBase58 encodes data the way Solana encodes public keys: as a plain
+// base58 integer, left-padded with '1' for each leading zero byte.
+func encodeBase58(data []byte) string {
+    zeros := 0
+    for zeros < len(data) && data[zeros] == 0 {
+        zeros++
+    }
+
+    num := new(big.Int).SetBytes(data)
+    mod := big.NewInt(58)
+    zero := big.NewInt(0)
+
+    var out []byte
+    for num.Cmp(zero) > 0 {
+        var rem big.Int
+        num.DivMod(num, mod, &rem)
+        out = append([]byte{base58Alphabet[rem.Int64()]}, out...)
+    }
+
+    return strings.Repeat("1", zeros) + string(out)
+}
+
+// Keypair is an ed25519 keypair, encoded for storage in a JSON config file.
+type Keypair struct {
+    PublicKey  string
+    PrivateKey string
+}
+
+// NewSolanaKeypair generates a fresh ed25519 keypair and encodes it the way
+// Solana wallets do: the public key as base58 (a Solana address). The
+// private key has no standard text encoding outside the Solana CLI's JSON
+// byte-array format, so it's stored as base64 - this config is never meant
+// to leave the host it's generated on.
+func NewSolanaKeypair() (*Keypair, error) {
+    pub, priv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate Solana keypair: %w", err)
+    }
+    return &Keypair{
+        PublicKey:  encodeBase58(pub),
+        PrivateKey: base64.StdEncoding.EncodeToString(priv),
+    }, nil
+}
+
+// NewSigningKeypair generates a fresh ed25519 keypair used to authenticate
+// privileged Telegram commands. Both halves are base64 since neither is
+// ever shown to a human as an address.
+func NewSigningKeypair() (*Keypair, error) {
+    pub, priv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate signing keypair: %w", err)
+    }
+    return &Keypair{
+        PublicKey:  base64.StdEncoding.EncodeToString(pub),
+        PrivateKey: base64.StdEncoding.EncodeToString(priv),
+    }, nil
+}
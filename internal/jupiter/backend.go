@@ -0,0 +1,123 @@
+package jupiter
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+
+    "github.com/sial-ari/solana-token-sniper/internal/router"
+    "github.com/ilkamo/jupiter-go/jupiter"
+)
+
+// solMint is wrapped SOL's mint address, the input side of every quote
+// this package asks for
+const solMint = "So11111111111111111111111111111111111111112"
+
+// backend adapts Jupiter's aggregator API to router.Backend, so Client can
+// register it alongside direct-DEX backends behind the same interface.
+type backend struct {
+    router.NoDepth
+    client *jupiter.ClientWithResponses
+}
+
+func newBackend(client *jupiter.ClientWithResponses) *backend {
+    return &backend{
+        NoDepth: router.NoDepth{Name: "jupiter"},
+        client:  client,
+    }
+}
+
+func (b *backend) Name() string { return "jupiter" }
+
+// GetQuote asks Jupiter's aggregator to route inputMint -> outputMint
+// across whatever pools it knows about
+func (b *backend) GetQuote(ctx context.Context, inputMint, outputMint string, amount float64, slippageBps int) (*router.Quote, error) {
+    response, err := b.client.GetQuoteWithResponse(ctx, &jupiter.GetQuoteParams{
+        InputMint:   inputMint,
+        OutputMint:  outputMint,
+        Amount:      int64(amount),
+        SlippageBps: &slippageBps,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to get quote: %w", err)
+    }
+    if response.JSON200 == nil {
+        return nil, fmt.Errorf("no valid quote response received")
+    }
+
+    quote := response.JSON200
+
+    outAmount, err := strconv.ParseFloat(quote.OutAmount, 64)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse quote outAmount %q: %w", quote.OutAmount, err)
+    }
+    minimumOutAmount, err := strconv.ParseFloat(quote.OtherAmountThreshold, 64)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse quote otherAmountThreshold %q: %w", quote.OtherAmountThreshold, err)
+    }
+    priceImpactPct, err := strconv.ParseFloat(quote.PriceImpactPct, 64)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse quote priceImpactPct %q: %w", quote.PriceImpactPct, err)
+    }
+
+    var price float64
+    if amount != 0 {
+        price = outAmount / amount
+    }
+
+    return &router.Quote{
+        InputMint:        inputMint,
+        OutputMint:       outputMint,
+        InAmount:         amount,
+        OutAmount:        outAmount,
+        Price:            price,
+        PriceImpactPct:   priceImpactPct,
+        MinimumOutAmount: minimumOutAmount,
+        Source:           "jupiter",
+    }, nil
+}
+
+// ExecuteSwap re-quotes quote's route (Jupiter's /swap endpoint needs the
+// full route plan, which a router.Quote doesn't carry) and builds the swap
+// transaction. The caller is responsible for signing and submitting
+// Signature.TxID, the same as the rest of this codebase's swap flow.
+func (b *backend) ExecuteSwap(ctx context.Context, quote *router.Quote, userPubKey string) (*router.Signature, error) {
+    slippageBps := 250
+    response, err := b.client.GetQuoteWithResponse(ctx, &jupiter.GetQuoteParams{
+        InputMint:   quote.InputMint,
+        OutputMint:  quote.OutputMint,
+        Amount:      int64(quote.InAmount),
+        SlippageBps: &slippageBps,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to get swap quote: %w", err)
+    }
+    if response.JSON200 == nil {
+        return nil, fmt.Errorf("no valid quote response received")
+    }
+
+    prioritizationFeeLamports := jupiter.SwapRequest_PrioritizationFeeLamports{}
+    if err := prioritizationFeeLamports.UnmarshalJSON([]byte(`"auto"`)); err != nil {
+        return nil, fmt.Errorf("error setting prioritization fee: %w", err)
+    }
+
+    dynamicComputeUnitLimit := true
+
+    swapResponse, err := b.client.PostSwapWithResponse(ctx, jupiter.PostSwapJSONRequestBody{
+        QuoteResponse:             *response.JSON200,
+        UserPublicKey:             userPubKey,
+        PrioritizationFeeLamports: &prioritizationFeeLamports,
+        DynamicComputeUnitLimit:   &dynamicComputeUnitLimit,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to execute swap: %w", err)
+    }
+    if swapResponse.JSON200 == nil {
+        return nil, fmt.Errorf("no valid swap response received")
+    }
+
+    return &router.Signature{
+        TxID:   swapResponse.JSON200.SwapTransaction,
+        Source: "jupiter",
+    }, nil
+}
@@ -3,45 +3,135 @@ package jupiter
 import (
     "context"
     "fmt"
-    "log"
     "sync"
     "time"
 
     "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
     "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/pubsub"
+    "github.com/sial-ari/solana-token-sniper/internal/router"
     "github.com/ilkamo/jupiter-go/jupiter"
 )
 
-// Client manages Jupiter API interactions and price monitoring
+// defaultSlippageBps is used for price-check quotes; swap callers that
+// need a different tolerance should be exposed as a parameter if this
+// package ever needs to vary it
+const defaultSlippageBps = 250
+
+// probeAmount is a small, fixed SOL amount (in lamports) used to price a
+// token without committing to a real trade size
+const probeAmount = 100000 // 0.0001 SOL
+
+// Client manages price monitoring and swap execution across a registry of
+// router.Backend implementations - Jupiter's aggregator by default, plus
+// whatever direct-DEX backends are registered via RegisterBackend - and
+// picks among their quotes according to policy
 type Client struct {
-    jupClient    *jupiter.Client
+    backends     *router.Router
+    policy       router.Policy
     db           *db.Database
     queueSize    int
     interval     time.Duration
     mutex        sync.RWMutex
     monitoredTokens map[string]bool
     done         chan struct{}
+
+    // bus is optional: when set, StartPriceMonitoring learns about tokens
+    // via a pubsub.TopicNewTokens subscription instead of polling the
+    // database queue, and publishes price/P&L updates for other consumers
+    bus pubsub.Bus
+
+    // logger defaults to a no-op and is upgraded to the context-carried
+    // logger once StartPriceMonitoring runs, so every line it emits carries
+    // a mint= field for the token it's acting on
+    logger *logger.Logger
 }
 
-// NewClient creates a new Jupiter client instance
+// NewClient creates a new Client with Jupiter registered as its only
+// backend; call RegisterBackend to add direct-DEX routes
 func NewClient(database *db.Database, queueSize int, interval int) (*Client, error) {
     jupClient, err := jupiter.NewClientWithResponses(jupiter.DefaultAPIURL)
     if err != nil {
         return nil, fmt.Errorf("failed to create Jupiter client: %w", err)
     }
 
+    backends := router.New()
+    backends.Register(newBackend(jupClient))
+
     return &Client{
-        jupClient:       jupClient,
+        backends:       backends,
+        policy:         router.PolicyBestPrice,
         db:             database,
         queueSize:      queueSize,
         interval:       time.Duration(interval) * time.Second,
         monitoredTokens: make(map[string]bool),
         done:           make(chan struct{}),
+        logger:         logger.FromContext(context.Background()).Named("jupiter"),
     }, nil
 }
 
+// RegisterBackend adds another router.Backend (e.g. a direct Raydium or
+// Orca pool) to the registry GetQuote and ExecuteSwap pick routes from.
+// It's most useful for brand-new pumpportal tokens Jupiter has no route
+// for yet.
+func (c *Client) RegisterBackend(b router.Backend) {
+    c.backends.Register(b)
+}
+
+// SetPolicy changes how GetQuote and ExecuteSwap pick among backends'
+// quotes; the default is router.PolicyBestPrice.
+func (c *Client) SetPolicy(policy router.Policy) {
+    c.policy = policy
+}
+
+// SetBus wires a pubsub bus into the client. Once set, StartPriceMonitoring
+// subscribes to pubsub.TopicNewTokens to learn about tokens instead of
+// polling the database queue, and publishes price and P&L updates so other
+// consumers (the Telegram notifier, external sinks) don't have to poll either.
+func (c *Client) SetBus(bus pubsub.Bus) {
+    c.mutex.Lock()
+    c.bus = bus
+    c.mutex.Unlock()
+}
+
+// trackNewTokens subscribes to pubsub.TopicNewTokens and adds every token
+// it sees to the monitored set
+func (c *Client) trackNewTokens(ctx context.Context) {
+    events, unsubscribe, err := c.bus.Subscribe(pubsub.TopicNewTokens)
+    if err != nil {
+        c.logger.Error("failed to subscribe to new token events", logger.F("error", err))
+        return
+    }
+    defer unsubscribe()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-c.done:
+            return
+        case event, ok := <-events:
+            if !ok {
+                return
+            }
+            if token, ok := event.Payload.(*models.NewToken); ok {
+                c.mutex.Lock()
+                c.monitoredTokens[token.Mint] = true
+                c.mutex.Unlock()
+            }
+        }
+    }
+}
+
 // StartPriceMonitoring begins monitoring prices for tokens in the queue
 func (c *Client) StartPriceMonitoring(ctx context.Context) {
+    c.logger = logger.FromContext(ctx).Named("jupiter")
+
+    if c.bus != nil {
+        go c.trackNewTokens(ctx)
+    }
+
     ticker := time.NewTicker(c.interval)
     defer ticker.Stop()
 
@@ -53,15 +143,33 @@ func (c *Client) StartPriceMonitoring(ctx context.Context) {
             return
         case <-ticker.C:
             if err := c.updatePrices(ctx); err != nil {
-                log.Printf("Error updating prices: %v", err)
+                c.logger.Error("failed to update prices", logger.F("error", err))
             }
         }
     }
 }
 
+// tokensToMonitor returns the tokens whose prices should be refreshed this
+// tick: the subscribed mints when a bus is wired up, otherwise the most
+// recent tokens from the database queue
+func (c *Client) tokensToMonitor() ([]models.NewToken, error) {
+    if c.bus == nil {
+        return c.db.GetTokensInQueue(c.queueSize)
+    }
+
+    c.mutex.RLock()
+    defer c.mutex.RUnlock()
+
+    tokens := make([]models.NewToken, 0, len(c.monitoredTokens))
+    for mint := range c.monitoredTokens {
+        tokens = append(tokens, models.NewToken{Mint: mint})
+    }
+    return tokens, nil
+}
+
 // updatePrices fetches current prices for all monitored tokens
 func (c *Client) updatePrices(ctx context.Context) error {
-    tokens, err := c.db.GetTokensInQueue(c.queueSize)
+    tokens, err := c.tokensToMonitor()
     if err != nil {
         return fmt.Errorf("failed to get tokens from queue: %w", err)
     }
@@ -74,9 +182,11 @@ func (c *Client) updatePrices(ctx context.Context) error {
         default:
         }
 
-        quote, err := c.getQuote(ctx, token.Mint)
+        tokenLogger := c.logger.With(logger.F("mint", token.Mint))
+
+        quote, err := c.GetQuote(ctx, token.Mint)
         if err != nil {
-            log.Printf("Error getting quote for %s: %v", token.Mint, err)
+            tokenLogger.Error("failed to get quote", logger.F("error", err))
             continue
         }
 
@@ -86,15 +196,16 @@ func (c *Client) updatePrices(ctx context.Context) error {
             Price:     quote.Price,
             Timestamp: time.Now(),
         }
-        
+
         if err := c.db.SaveTokenPrice(pricePoint); err != nil {
-            log.Printf("Error saving price for %s: %v", token.Mint, err)
+            tokenLogger.Error("failed to save price", logger.F("error", err))
             continue
         }
+        c.publish(pubsub.TopicPricesUpdated, pricePoint)
 
         // Update profit/loss calculations
         if err := c.updateProfitLoss(ctx, token.Mint); err != nil {
-            log.Printf("Error updating P&L for %s: %v", token.Mint, err)
+            tokenLogger.Error("failed to update P&L", logger.F("error", err))
             continue
         }
     }
@@ -102,27 +213,12 @@ func (c *Client) updatePrices(ctx context.Context) error {
     return nil
 }
 
-// getQuote retrieves the current price quote for a token
-func (c *Client) getQuote(ctx context.Context, outputMint string) (*jupiter.QuoteResponse, error) {
-    slippageBps := 250
-    inputAmount := int64(100000) // 0.0001 SOL for price check
-
-    response, err := c.jupClient.GetQuoteWithResponse(ctx, &jupiter.GetQuoteParams{
-        InputMint:   "So11111111111111111111111111111111111111112", // SOL
-        OutputMint:  outputMint,
-        Amount:      inputAmount,
-        SlippageBps: &slippageBps,
-    })
-    
-    if err != nil {
-        return nil, fmt.Errorf("failed to get quote: %w", err)
-    }
-
-    if response.JSON200 == nil {
-        return nil, fmt.Errorf("no valid quote response received")
-    }
-
-    return response.JSON200, nil
+// GetQuote returns a best-route quote, picked from every registered
+// backend by c.policy, for swapping a small probe amount of SOL into
+// mint. A backend with no route for mint (common for a token seconds old)
+// doesn't fail the call as long as another backend has one.
+func (c *Client) GetQuote(ctx context.Context, mint string) (*router.Quote, error) {
+    return c.backends.BestQuote(ctx, solMint, mint, probeAmount, defaultSlippageBps, c.policy)
 }
 
 // updateProfitLoss calculates and stores current P&L for a token
@@ -147,57 +243,51 @@ func (c *Client) updateProfitLoss(ctx context.Context, mint string) error {
         LastUpdated:   time.Now(),
     }
 
-    return c.db.UpdateProfitLoss(pl)
-}
+    if err := c.db.UpdateProfitLoss(pl); err != nil {
+        return err
+    }
+    c.publish(pubsub.TopicPLUpdated, pl)
 
-// ExecuteSwap performs a token swap using Jupiter
-func (c *Client) ExecuteSwap(ctx context.Context, mint string, solAmount float64, userPubKey string) error {
-    // Convert SOL amount to lamports
-    lamports := int64(solAmount * 1e9)
-    
-    // Get quote for the swap
-    slippageBps := 250
-    response, err := c.jupClient.GetQuoteWithResponse(ctx, &jupiter.GetQuoteParams{
-        InputMint:   "So11111111111111111111111111111111111111112",
-        OutputMint:  mint,
-        Amount:      lamports,
-        SlippageBps: &slippageBps,
-    })
+    return nil
+}
 
-    if err != nil {
-        return fmt.Errorf("failed to get swap quote: %w", err)
-    }
+// publish fans payload out on topic when a bus is configured; it's a no-op
+// otherwise so callers don't need to check for nil themselves
+func (c *Client) publish(topic string, payload interface{}) {
+    c.mutex.RLock()
+    bus := c.bus
+    c.mutex.RUnlock()
 
-    if response.JSON200 == nil {
-        return fmt.Errorf("no valid quote response received")
+    if bus == nil {
+        return
     }
-
-    // Set up swap parameters
-    prioritizationFeeLamports := jupiter.SwapRequest_PrioritizationFeeLamports{}
-    if err = prioritizationFeeLamports.UnmarshalJSON([]byte(`"auto"`)); err != nil {
-        return fmt.Errorf("error setting prioritization fee: %w", err)
+    if err := bus.Publish(topic, payload); err != nil {
+        c.logger.Error("failed to publish event", logger.F("topic", topic), logger.F("error", err))
     }
+}
 
-    dynamicComputeUnitLimit := true
-
-    // Execute the swap
-    swapResponse, err := c.jupClient.PostSwapWithResponse(ctx, jupiter.PostSwapJSONRequestBody{
-        QuoteResponse:             *response.JSON200,
-        UserPublicKey:            userPubKey,
-        PrioritizationFeeLamports: &prioritizationFeeLamports,
-        DynamicComputeUnitLimit:   &dynamicComputeUnitLimit,
-    })
+// ExecuteSwap swaps solAmount SOL into mint, picking the best route among
+// every registered backend by c.policy and executing the swap against
+// whichever backend produced it.
+func (c *Client) ExecuteSwap(ctx context.Context, mint string, solAmount float64, userPubKey string) error {
+    lamports := solAmount * 1e9
 
+    quote, err := c.backends.BestQuote(ctx, solMint, mint, lamports, defaultSlippageBps, c.policy)
     if err != nil {
-        return fmt.Errorf("failed to execute swap: %w", err)
+        return fmt.Errorf("failed to get swap quote: %w", err)
     }
 
-    if swapResponse.JSON200 == nil {
-        return fmt.Errorf("no valid swap response received")
+    sig, err := c.backends.ExecuteSwap(ctx, quote, userPubKey)
+    if err != nil {
+        return fmt.Errorf("failed to execute swap: %w", err)
     }
 
-    // Log successful swap
-    log.Printf("Successful swap for token %s, amount: %f SOL", mint, solAmount)
+    c.logger.Info("swap executed",
+        logger.F("mint", mint),
+        logger.F("sol_amount", solAmount),
+        logger.F("source", quote.Source),
+        logger.F("tx", sig.TxID),
+    )
     return nil
 }
 
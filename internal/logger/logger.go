@@ -1,83 +1,288 @@
-// internal/logger/logger.go
+// Package logger provides a structured, leveled logger with field
+// propagation, size-based rotation, and pattern-based per-component
+// filtering, replacing the earlier plain-text Logger.
 package logger
 
 import (
+    "context"
+    "encoding/json"
     "fmt"
-    "os"
-    "time"
     "path/filepath"
+    "strings"
     "sync"
+    "time"
 )
 
-type Logger struct {
-    logFile *os.File
-    mu      sync.Mutex
+// Level is a log severity. Levels are ordered: DEBUG < INFO < WARN < ERROR < FATAL.
+type Level int
+
+const (
+    DEBUG Level = iota
+    INFO
+    WARN
+    ERROR
+    FATAL
+)
+
+func (l Level) String() string {
+    switch l {
+    case DEBUG:
+        return "DEBUG"
+    case INFO:
+        return "INFO"
+    case WARN:
+        return "WARN"
+    case ERROR:
+        return "ERROR"
+    case FATAL:
+        return "FATAL"
+    default:
+        return "UNKNOWN"
+    }
 }
 
-type TimedOperation struct {
-    StartTime time.Time
-    Name      string
-    logger    *Logger
+// ParseLevel parses a level name (case-insensitive); unknown names default to INFO
+func ParseLevel(name string) Level {
+    switch strings.ToUpper(name) {
+    case "DEBUG":
+        return DEBUG
+    case "WARN", "WARNING":
+        return WARN
+    case "ERROR":
+        return ERROR
+    case "FATAL":
+        return FATAL
+    default:
+        return INFO
+    }
 }
 
-func NewLogger(logPath string) (*Logger, error) {
-    // Create logs directory if it doesn't exist
-    logDir := filepath.Dir(logPath)
-    if err := os.MkdirAll(logDir, 0755); err != nil {
-        return nil, fmt.Errorf("failed to create log directory: %w", err)
+// Format is the log line encoding
+type Format string
+
+const (
+    FormatJSON   Format = "json"
+    FormatLogfmt Format = "logfmt"
+)
+
+// ParseFormat parses a format name (case-insensitive); unknown names default to logfmt
+func ParseFormat(name string) Format {
+    if strings.EqualFold(name, string(FormatJSON)) {
+        return FormatJSON
     }
+    return FormatLogfmt
+}
 
-    // Open log file with append mode
-    file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// Field is a single structured key/value pair attached to a log line
+type Field struct {
+    Key   string
+    Value interface{}
+}
+
+// F builds a Field; a small convenience so call sites read as
+// logger.F("mint", token.Mint) rather than a struct literal
+func F(key string, value interface{}) Field {
+    return Field{Key: key, Value: value}
+}
+
+// Options configures a Logger
+type Options struct {
+    Level     Level
+    Format    Format
+    MaxSizeMB int // rotate the log file once it exceeds this size; 0 disables rotation
+}
+
+// Logger is a structured, leveled logger. A Logger is immutable from the
+// caller's perspective: With returns a new Logger sharing the same
+// destination and filters but carrying additional fields, so a mint- or
+// chat-scoped logger can be derived without affecting its parent.
+type Logger struct {
+    out    *rotatingFile
+    level  Level
+    format Format
+    fields []Field
+    // component identifies the subsystem this logger belongs to (e.g.
+    // "websocket", "jupiter", "telegram"); matched against the
+    // include/exclude patterns
+    component string
+
+    filters *filterSet
+}
+
+// filterSet is shared by a Logger and every Logger derived from it via
+// With/Named, so pattern rules registered anywhere apply everywhere
+type filterSet struct {
+    mu       sync.RWMutex
+    includes []string
+    excludes []string
+}
+
+// NewLogger creates a Logger that writes to logPath, creating the log
+// directory and rotating the file per opts.MaxSizeMB
+func NewLogger(logPath string, opts Options) (*Logger, error) {
+    out, err := newRotatingFile(logPath, opts.MaxSizeMB)
     if err != nil {
         return nil, fmt.Errorf("failed to open log file: %w", err)
     }
 
+    format := opts.Format
+    if format == "" {
+        format = FormatLogfmt
+    }
+
     return &Logger{
-        logFile: file,
+        out:     out,
+        level:   opts.Level,
+        format:  format,
+        filters: &filterSet{},
     }, nil
 }
 
+// Close closes the underlying log file
 func (l *Logger) Close() error {
-    return l.logFile.Close()
+    return l.out.Close()
 }
 
-func (l *Logger) logEntry(level, message string, elapsed *time.Duration) {
-    l.mu.Lock()
-    defer l.mu.Unlock()
+// With returns a child Logger that includes fields on every subsequent line
+func (l *Logger) With(fields ...Field) *Logger {
+    child := *l
+    child.fields = append(append([]Field{}, l.fields...), fields...)
+    return &child
+}
+
+// Named returns a child Logger tagged with a component name, which
+// AddEverythingFromPattern/ExcludeFromPattern match against
+func (l *Logger) Named(component string) *Logger {
+    child := *l
+    child.component = component
+    return &child
+}
+
+// AddEverythingFromPattern forces every message from components matching
+// the glob pattern to be logged regardless of level
+func (l *Logger) AddEverythingFromPattern(pattern string) {
+    l.filters.mu.Lock()
+    defer l.filters.mu.Unlock()
+    l.filters.includes = append(l.filters.includes, pattern)
+}
+
+// ExcludeFromPattern suppresses every message from components matching the
+// glob pattern regardless of level
+func (l *Logger) ExcludeFromPattern(pattern string) {
+    l.filters.mu.Lock()
+    defer l.filters.mu.Unlock()
+    l.filters.excludes = append(l.filters.excludes, pattern)
+}
+
+// allowed reports whether a line at level should be emitted for the
+// logger's component, honoring the include/exclude patterns ahead of the
+// plain level check
+func (l *Logger) allowed(level Level) bool {
+    l.filters.mu.RLock()
+    defer l.filters.mu.RUnlock()
+
+    for _, pattern := range l.filters.excludes {
+        if matched, _ := filepath.Match(pattern, l.component); matched {
+            return false
+        }
+    }
+    for _, pattern := range l.filters.includes {
+        if matched, _ := filepath.Match(pattern, l.component); matched {
+            return true
+        }
+    }
+    return level >= l.level
+}
+
+func (l *Logger) log(level Level, msg string, fields ...Field) {
+    if !l.allowed(level) {
+        return
+    }
 
-    timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-    var logMessage string
-    
-    if elapsed != nil {
-        logMessage = fmt.Sprintf("[%s] %s: %s (took: %v)\n", timestamp, level, message, *elapsed)
-    } else {
-        logMessage = fmt.Sprintf("[%s] %s: %s\n", timestamp, level, message)
+    all := make([]Field, 0, len(l.fields)+len(fields)+1)
+    if l.component != "" {
+        all = append(all, F("component", l.component))
     }
+    all = append(all, l.fields...)
+    all = append(all, fields...)
 
-    l.logFile.WriteString(logMessage)
-    // Also print to stdout for development
-    fmt.Print(logMessage)
+    l.out.WriteString(l.render(level, msg, all) + "\n")
 }
 
-func (l *Logger) Info(message string) {
-    l.logEntry("INFO", message, nil)
+func (l *Logger) render(level Level, msg string, fields []Field) string {
+    if l.format == FormatJSON {
+        entry := map[string]interface{}{
+            "timestamp": time.Now().Format(time.RFC3339Nano),
+            "level":     level.String(),
+            "message":   msg,
+        }
+        for _, f := range fields {
+            entry[f.Key] = f.Value
+        }
+        data, err := json.Marshal(entry)
+        if err != nil {
+            return fmt.Sprintf("ERROR: failed to marshal log entry: %v", err)
+        }
+        return string(data)
+    }
+
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "time=%s level=%s msg=%q", time.Now().Format(time.RFC3339Nano), level.String(), msg)
+    for _, f := range fields {
+        fmt.Fprintf(&sb, " %s=%v", f.Key, f.Value)
+    }
+    return sb.String()
 }
 
-func (l *Logger) Error(message string) {
-    l.logEntry("ERROR", message, nil)
+func (l *Logger) Debug(msg string, fields ...Field) { l.log(DEBUG, msg, fields...) }
+func (l *Logger) Info(msg string, fields ...Field)  { l.log(INFO, msg, fields...) }
+func (l *Logger) Warn(msg string, fields ...Field)  { l.log(WARN, msg, fields...) }
+func (l *Logger) Error(msg string, fields ...Field) { l.log(ERROR, msg, fields...) }
+func (l *Logger) Fatal(msg string, fields ...Field) { l.log(FATAL, msg, fields...) }
+
+// TimedOperation tracks an in-flight operation so its duration can be
+// emitted as a structured span when it ends
+type TimedOperation struct {
+    startTime time.Time
+    name      string
+    logger    *Logger
 }
 
+// TimeOperation starts timing an operation named name
 func (l *Logger) TimeOperation(name string) *TimedOperation {
     return &TimedOperation{
-        StartTime: time.Now(),
-        Name:      name,
+        startTime: time.Now(),
+        name:      name,
         logger:    l,
     }
 }
 
+// End logs the operation's duration as a structured span and returns it
 func (t *TimedOperation) End() time.Duration {
-    elapsed := time.Since(t.StartTime)
-    t.logger.logEntry("TIMING", fmt.Sprintf("%s completed", t.Name), &elapsed)
+    elapsed := time.Since(t.startTime)
+    t.logger.Info("operation completed",
+        F("operation", t.name),
+        F("duration_ms", elapsed.Milliseconds()),
+    )
     return elapsed
 }
+
+type contextKey struct{}
+
+// WithContext returns a context carrying l, retrievable via FromContext
+func WithContext(ctx context.Context, l *Logger) context.Context {
+    return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Logger carried by ctx, or a discarding no-op
+// Logger if none was attached
+func FromContext(ctx context.Context) *Logger {
+    if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+        return l
+    }
+    return noop
+}
+
+// noop is returned by FromContext when no logger was attached to the
+// context, so callers never need a nil check
+var noop = &Logger{out: discardRotatingFile(), level: FATAL + 1, format: FormatLogfmt, filters: &filterSet{}}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// rotatingFile wraps an *os.File, rotating it to a timestamped backup once
+// it exceeds maxSizeMB. A maxSizeMB of 0 disables rotation entirely.
+type rotatingFile struct {
+    mu        sync.Mutex
+    path      string
+    file      *os.File
+    size      int64
+    maxBytes  int64
+    discard   bool
+}
+
+// newRotatingFile opens path for appending, creating its directory if
+// needed, and rotating immediately if it already exceeds maxSizeMB
+func newRotatingFile(path string, maxSizeMB int) (*rotatingFile, error) {
+    if dir := filepath.Dir(path); dir != "." {
+        if err := os.MkdirAll(dir, 0755); err != nil {
+            return nil, fmt.Errorf("failed to create log directory: %w", err)
+        }
+    }
+
+    file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return nil, err
+    }
+
+    info, err := file.Stat()
+    if err != nil {
+        file.Close()
+        return nil, err
+    }
+
+    rf := &rotatingFile{
+        path:     path,
+        file:     file,
+        size:     info.Size(),
+        maxBytes: int64(maxSizeMB) * 1024 * 1024,
+    }
+
+    if rf.maxBytes > 0 && rf.size >= rf.maxBytes {
+        if err := rf.rotate(); err != nil {
+            file.Close()
+            return nil, err
+        }
+    }
+
+    return rf, nil
+}
+
+// discardRotatingFile returns a rotatingFile that silently drops everything
+// written to it, used by the package's no-op fallback Logger
+func discardRotatingFile() *rotatingFile {
+    return &rotatingFile{discard: true}
+}
+
+// WriteString appends line to the file, rotating first if it would push
+// the file over maxBytes
+func (rf *rotatingFile) WriteString(line string) {
+    if rf.discard {
+        return
+    }
+
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+
+    if rf.maxBytes > 0 && rf.size+int64(len(line)) > rf.maxBytes {
+        if err := rf.rotate(); err != nil {
+            fmt.Fprintf(os.Stderr, "logger: failed to rotate %s: %v\n", rf.path, err)
+        }
+    }
+
+    n, err := rf.file.WriteString(line)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "logger: failed to write to %s: %v\n", rf.path, err)
+        return
+    }
+    rf.size += int64(n)
+}
+
+// rotate closes the current file, renames it aside with a timestamp
+// suffix, and reopens path fresh. Callers must hold rf.mu.
+func (rf *rotatingFile) rotate() error {
+    if err := rf.file.Close(); err != nil {
+        return err
+    }
+
+    backup := fmt.Sprintf("%s.%s", rf.path, time.Now().Format("20060102-150405"))
+    if err := os.Rename(rf.path, backup); err != nil {
+        return err
+    }
+
+    file, err := os.OpenFile(rf.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return err
+    }
+
+    rf.file = file
+    rf.size = 0
+    return nil
+}
+
+// Close closes the underlying file
+func (rf *rotatingFile) Close() error {
+    if rf.discard {
+        return nil
+    }
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    return rf.file.Close()
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// KlinePeriod is a candle bucket width, named the way most exchange APIs
+// name their kline intervals.
+type KlinePeriod string
+
+const (
+    Period1m  KlinePeriod = "1m"
+    Period5m  KlinePeriod = "5m"
+    Period15m KlinePeriod = "15m"
+    Period1h  KlinePeriod = "1h"
+    Period4h  KlinePeriod = "4h"
+    Period1d  KlinePeriod = "1d"
+)
+
+// AllKlinePeriods lists every period a candle is kept for. Callers that
+// aggregate or backfill candles should iterate this slice rather than
+// hardcoding the set of periods.
+var AllKlinePeriods = []KlinePeriod{Period1m, Period5m, Period15m, Period1h, Period4h, Period1d}
+
+// Duration returns the bucket width for p, or zero if p isn't one of the
+// periods in AllKlinePeriods.
+func (p KlinePeriod) Duration() time.Duration {
+    switch p {
+    case Period1m:
+        return time.Minute
+    case Period5m:
+        return 5 * time.Minute
+    case Period15m:
+        return 15 * time.Minute
+    case Period1h:
+        return time.Hour
+    case Period4h:
+        return 4 * time.Hour
+    case Period1d:
+        return 24 * time.Hour
+    default:
+        return 0
+    }
+}
+
+// OpenTime truncates t down to the start of the bucket it falls in for
+// period p.
+func (p KlinePeriod) OpenTime(t time.Time) time.Time {
+    return t.UTC().Truncate(p.Duration())
+}
+
+// Kline is one OHLCV candle for a token over a single period bucket.
+// Volume counts the number of price ticks aggregated into the candle,
+// since price_history carries no trade size - it's a proxy for how
+// actively a token traded in the bucket, not a token or SOL amount.
+type Kline struct {
+    Mint     string      `json:"mint"`
+    Period   KlinePeriod `json:"period"`
+    OpenTime time.Time   `json:"openTime"`
+    Open     float64     `json:"open"`
+    High     float64     `json:"high"`
+    Low      float64     `json:"low"`
+    Close    float64     `json:"close"`
+    Volume   float64     `json:"volume"`
+}
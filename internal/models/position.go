@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Position tracks one open (or recently closed) token position so the
+// strategy engine can resume managing its exit after a restart instead of
+// losing track of what it already bought.
+type Position struct {
+    Mint string `json:"mint"`
+
+    EntryPrice     float64   `json:"entryPrice"`
+    EntrySolAmount float64   `json:"entrySolAmount"`
+    EntryTime      time.Time `json:"entryTime"`
+
+    // MaxPrice is the highest price observed since entry, used to compute
+    // trailing-stop drawdown.
+    MaxPrice float64 `json:"maxPrice"`
+
+    // RemainingPct is the fraction (0-1) of the original position still
+    // held; it decreases as take-profit levels or a full stop-out sell it
+    // off.
+    RemainingPct float64 `json:"remainingPct"`
+
+    // TakeProfitsFired counts how many of the configured take-profit
+    // levels, in ascending order, have already sold their slice.
+    TakeProfitsFired int `json:"takeProfitsFired"`
+
+    // RealizedPL is the cumulative SOL profit or loss booked by exits so
+    // far; it only grows in magnitude as more of RemainingPct sells off.
+    RealizedPL float64 `json:"realizedPl"`
+
+    Closed   bool      `json:"closed"`
+    ClosedAt time.Time `json:"closedAt"`
+}
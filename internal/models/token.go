@@ -25,6 +25,18 @@ type TokenPrice struct {
     Timestamp time.Time `json:"timestamp"`
 }
 
+// OraclePrice represents an authoritative on-chain price reading for a token,
+// e.g. from a Pyth price account, alongside its reported confidence interval.
+type OraclePrice struct {
+    Mint         string    `json:"mint"`
+    Price        float64   `json:"price"`
+    Confidence   float64   `json:"confidence"`
+    Expo         int32     `json:"expo"`
+    PublishSlot  uint64    `json:"publishSlot"`
+    Source       string    `json:"source"`
+    Timestamp    time.Time `json:"timestamp"`
+}
+
 type TokenProfitLoss struct {
     Mint           string    `json:"mint"`
     InitialPrice   float64   `json:"initialPrice"`
@@ -1,114 +1,508 @@
-// internal/performance/monitor.go
+// Package performance tracks per-operation latency and success/failure
+// counts for the sniper pipeline, in Prometheus TSDB-flavored shape:
+// O(1) bucketed counters for the exposition format, bounded ring
+// buffers for windowed percentile queries, and an alert-rule system
+// that watches both.
 package performance
 
 import (
-    "sync"
-    "time"
-    "github.com/sial-ari/solana-token-sniper/internal/logger"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sial-ari/solana-token-sniper/internal/logger"
 )
 
 type OperationType string
 
 const (
-    OpTokenCreate    OperationType = "token_create"
-    OpPriceCheck     OperationType = "price_check"
-    OpJupiterSwap    OperationType = "jupiter_swap"
-    OpDatabaseWrite  OperationType = "db_write"
-    OpDatabaseRead   OperationType = "db_read"
+	OpTokenCreate   OperationType = "token_create"
+	OpPriceCheck    OperationType = "price_check"
+	OpJupiterSwap   OperationType = "jupiter_swap"
+	OpDatabaseWrite OperationType = "db_write"
+	OpDatabaseRead  OperationType = "db_read"
 )
 
+// latencyBuckets are cumulative upper bounds, in seconds, mirroring the
+// "le" buckets of a Prometheus histogram.
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metric is a single recorded operation outcome.
 type Metric struct {
-    OperationType OperationType
-    Duration     time.Duration
-    Timestamp    time.Time
-    Success      bool
+	OperationType OperationType
+	Duration      time.Duration
+	Timestamp     time.Time
+	Success       bool
+}
+
+// ringSize bounds how many recent samples are kept per OperationType for
+// windowed queries, so memory stays flat no matter how many operations
+// are ever recorded.
+const ringSize = 4096
+
+// sampleRing is a fixed-capacity circular buffer of recent metrics for
+// one OperationType, oldest entries overwritten once full.
+type sampleRing struct {
+	samples [ringSize]Metric
+	next    int
+	filled  bool
+}
+
+func (r *sampleRing) add(m Metric) {
+	r.samples[r.next] = m
+	r.next = (r.next + 1) % ringSize
+	if r.next == 0 {
+		r.filled = true
+	}
+}
+
+// snapshot returns the ring's contents in chronological order.
+func (r *sampleRing) snapshot() []Metric {
+	if !r.filled {
+		out := make([]Metric, r.next)
+		copy(out, r.samples[:r.next])
+		return out
+	}
+	out := make([]Metric, ringSize)
+	n := copy(out, r.samples[r.next:])
+	copy(out[n:], r.samples[:r.next])
+	return out
 }
 
+// histogram is a cumulative, HdrHistogram-style bucketed latency counter.
+// Observing a sample is O(len(latencyBuckets)); it never grows with the
+// number of samples observed.
+type histogram struct {
+	bucketCounts []uint64
+	sumSeconds   float64
+	count        uint64
+	successCount uint64
+}
+
+func newHistogram() *histogram {
+	return &histogram{bucketCounts: make([]uint64, len(latencyBuckets))}
+}
+
+func (h *histogram) observe(seconds float64, success bool) {
+	h.count++
+	h.sumSeconds += seconds
+	if success {
+		h.successCount++
+	}
+	for i, le := range latencyBuckets {
+		if seconds <= le {
+			h.bucketCounts[i]++
+		}
+	}
+}
+
+// AlertCondition selects what an AlertRule watches.
+type AlertCondition int
+
+const (
+	SuccessRateBelow AlertCondition = iota
+	P99LatencyAbove
+)
+
+// AlertRule fires Callback when, over Window, OpType's success rate
+// drops below Threshold (SuccessRateBelow, Threshold in percent) or its
+// p99 latency exceeds Threshold (P99LatencyAbove, Threshold in seconds).
+// Firing is edge-triggered: Callback runs once when the condition
+// becomes true, not on every subsequent breaching sample.
+type AlertRule struct {
+	Name      string
+	OpType    OperationType
+	Condition AlertCondition
+	Threshold float64
+	Window    time.Duration
+	Callback  func(rule AlertRule, value float64)
+}
+
+// defaultEWMATau is the time constant used for an operation's EWMA
+// latency estimate until SetEWMATau overrides it.
+const defaultEWMATau = 60 * time.Second
+
+type opState struct {
+	ring *sampleRing
+	hist *histogram
+
+	ewma       float64 // seconds; zero value means "no samples observed yet"
+	ewmaLast   time.Time
+	ewmaTau    time.Duration
+	ewmaWarmed bool
+}
+
+// observeEWMA folds seconds into the op's exponentially weighted moving
+// average. The first sample seeds the average directly rather than
+// decaying from zero, since there's nothing to decay from yet. Later
+// samples are weighted by alpha = 1 - exp(-elapsed/tau), so a sample
+// arriving soon after the last one shifts the average less than one
+// arriving after a long gap.
+func (s *opState) observeEWMA(seconds float64, now time.Time) {
+	if !s.ewmaWarmed {
+		s.ewma = seconds
+		s.ewmaLast = now
+		s.ewmaWarmed = true
+		return
+	}
+
+	tau := s.ewmaTau
+	if tau <= 0 {
+		tau = defaultEWMATau
+	}
+
+	elapsed := now.Sub(s.ewmaLast).Seconds()
+	alpha := 1 - math.Exp(-elapsed/tau.Seconds())
+	s.ewma += alpha * (seconds - s.ewma)
+	s.ewmaLast = now
+}
+
+// Monitor tracks per-operation latency and success/failure counts and
+// evaluates alert rules as new metrics are recorded.
 type Monitor struct {
-    metrics []Metric
-    mu      sync.RWMutex
-    logger  *logger.Logger
+	mu        sync.RWMutex
+	ops       map[OperationType]*opState
+	rules     []AlertRule
+	firing    map[string]bool    // rule name -> currently breaching, for edge-triggering
+	gauges    map[string]float64 // arbitrary point-in-time values, e.g. in-flight request counts
+	logger    *logger.Logger
+	retention time.Duration
+	stop      chan struct{}
+}
+
+// SetGauge records name's current value, overwriting any previous
+// value. Unlike the histogram-backed counters RecordMetric feeds, a
+// gauge isn't cumulative - it's for point-in-time values like an
+// in-flight request count that can go up or down.
+func (m *Monitor) SetGauge(name string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gauges == nil {
+		m.gauges = make(map[string]float64)
+	}
+	m.gauges[name] = value
+}
+
+// NewMonitor starts a Monitor whose ring buffers are pruned of samples
+// older than retention every retention/10 (floor one second), so callers
+// don't need to call PruneOldMetrics themselves.
+func NewMonitor(l *logger.Logger, retention time.Duration) *Monitor {
+	m := &Monitor{
+		ops:       make(map[OperationType]*opState),
+		firing:    make(map[string]bool),
+		logger:    l,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+	go m.pruneLoop()
+	return m
 }
 
-func NewMonitor(l *logger.Logger) *Monitor {
-    return &Monitor{
-        metrics: make([]Metric, 0),
-        logger:  l,
-    }
+// Close stops the background pruning goroutine.
+func (m *Monitor) Close() {
+	close(m.stop)
 }
 
+func (m *Monitor) pruneLoop() {
+	interval := m.retention / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.PruneOldMetrics(m.retention)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *Monitor) state(opType OperationType) *opState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.ops[opType]
+	if !ok {
+		s = &opState{ring: &sampleRing{}, hist: newHistogram()}
+		m.ops[opType] = s
+	}
+	return s
+}
+
+// RecordMetric records an operation outcome and evaluates alert rules
+// registered for opType.
 func (m *Monitor) RecordMetric(opType OperationType, duration time.Duration, success bool) {
-    m.mu.Lock()
-    defer m.mu.Unlock()
+	metric := Metric{
+		OperationType: opType,
+		Duration:      duration,
+		Timestamp:     time.Now(),
+		Success:       success,
+	}
+
+	s := m.state(opType)
+	m.mu.Lock()
+	s.ring.add(metric)
+	s.hist.observe(duration.Seconds(), success)
+	if success {
+		s.observeEWMA(duration.Seconds(), metric.Timestamp)
+	}
+	m.mu.Unlock()
+
+	m.logger.Debug("operation completed",
+		logger.F("operation", string(opType)),
+		logger.F("duration", duration.String()),
+		logger.F("success", success),
+	)
+
+	m.evaluateRules(opType)
+}
+
+// AddAlertRule registers rule for future evaluation. Rules are
+// evaluated synchronously after every RecordMetric call for their
+// OpType.
+func (m *Monitor) AddAlertRule(rule AlertRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule)
+}
+
+func (m *Monitor) evaluateRules(opType OperationType) {
+	m.mu.RLock()
+	var toCheck []AlertRule
+	for _, rule := range m.rules {
+		if rule.OpType == opType {
+			toCheck = append(toCheck, rule)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, rule := range toCheck {
+		var breaching bool
+		var value float64
+
+		switch rule.Condition {
+		case SuccessRateBelow:
+			value = m.GetSuccessRate(rule.OpType, rule.Window)
+			breaching = value < rule.Threshold
+		case P99LatencyAbove:
+			value = m.GetPercentile(rule.OpType, 99, rule.Window).Seconds()
+			breaching = value > rule.Threshold
+		}
+
+		m.mu.Lock()
+		was := m.firing[rule.Name]
+		m.firing[rule.Name] = breaching
+		m.mu.Unlock()
 
-    metric := Metric{
-        OperationType: opType,
-        Duration:     duration,
-        Timestamp:    time.Now(),
-        Success:      success,
-    }
+		if breaching && !was {
+			rule.Callback(rule, value)
+		}
+	}
+}
 
-    m.metrics = append(m.metrics, metric)
-    m.logger.Info(fmt.Sprintf("Operation %s completed in %v (success: %v)", opType, duration, success))
+func windowed(samples []Metric, window time.Duration) []Metric {
+	cutoff := time.Now().Add(-window)
+	out := samples[:0]
+	for _, s := range samples {
+		if s.Timestamp.After(cutoff) {
+			out = append(out, s)
+		}
+	}
+	return out
 }
 
+// GetAverageLatency returns the mean duration of successful opType
+// operations within window. Bounded by the ring buffer's fixed
+// capacity, so this runs in O(1) relative to total operations recorded.
 func (m *Monitor) GetAverageLatency(opType OperationType, window time.Duration) time.Duration {
-    m.mu.RLock()
-    defer m.mu.RUnlock()
+	s := m.state(opType)
+	m.mu.RLock()
+	samples := s.ring.snapshot()
+	m.mu.RUnlock()
+
+	samples = windowed(samples, window)
 
-    var total time.Duration
-    var count int
+	var total time.Duration
+	var count int
+	for _, sample := range samples {
+		if sample.Success {
+			total += sample.Duration
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
 
-    cutoff := time.Now().Add(-window)
-    for _, metric := range m.metrics {
-        if metric.OperationType == opType && metric.Timestamp.After(cutoff) && metric.Success {
-            total += metric.Duration
-            count++
-        }
-    }
+// SetEWMATau sets the time constant opType's EWMA latency estimate
+// decays with, overriding defaultEWMATau. A shorter tau tracks recent
+// samples more aggressively; a longer one smooths over more history.
+func (m *Monitor) SetEWMATau(opType OperationType, tau time.Duration) {
+	s := m.state(opType)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ewmaTau = tau
+}
 
-    if count == 0 {
-        return 0
-    }
+// GetEWMALatency returns opType's exponentially weighted moving average
+// latency over successful operations. Unlike GetAverageLatency's flat
+// window mean, a sample's influence decays continuously with its age, so
+// a shift in network conditions shows up immediately rather than being
+// diluted by a 5-minute-old flat window - this is what the strategy
+// layer should consult when deciding whether current conditions still
+// support a swap. GetAverageLatency remains available for callers that
+// specifically want the windowed mean.
+func (m *Monitor) GetEWMALatency(opType OperationType) time.Duration {
+	s := m.state(opType)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !s.ewmaWarmed {
+		return 0
+	}
+	return time.Duration(s.ewma * float64(time.Second))
+}
 
-    return total / time.Duration(count)
+// EstimatedETA projects how long a swap entering a queue of queueDepth
+// pending operations of opType would wait, assuming each one takes
+// opType's current EWMA latency to complete.
+func (m *Monitor) EstimatedETA(opType OperationType, queueDepth int) time.Duration {
+	return m.GetEWMALatency(opType) * time.Duration(queueDepth)
 }
 
+// GetSuccessRate returns the percentage (0-100) of opType operations
+// that succeeded within window.
 func (m *Monitor) GetSuccessRate(opType OperationType, window time.Duration) float64 {
-    m.mu.RLock()
-    defer m.mu.RUnlock()
+	s := m.state(opType)
+	m.mu.RLock()
+	samples := s.ring.snapshot()
+	m.mu.RUnlock()
+
+	samples = windowed(samples, window)
+
+	if len(samples) == 0 {
+		return 0
+	}
+	var successful int
+	for _, sample := range samples {
+		if sample.Success {
+			successful++
+		}
+	}
+	return float64(successful) / float64(len(samples)) * 100
+}
 
-    var total, successful int
-    cutoff := time.Now().Add(-window)
+// GetPercentile returns the p-th percentile (e.g. 50, 95, 99) latency of
+// opType operations within window, computed from the bounded ring
+// buffer rather than the full history.
+func (m *Monitor) GetPercentile(opType OperationType, p float64, window time.Duration) time.Duration {
+	s := m.state(opType)
+	m.mu.RLock()
+	samples := s.ring.snapshot()
+	m.mu.RUnlock()
 
-    for _, metric := range m.metrics {
-        if metric.OperationType == opType && metric.Timestamp.After(cutoff) {
-            total++
-            if metric.Success {
-                successful++
-            }
-        }
-    }
+	samples = windowed(samples, window)
+	if len(samples) == 0 {
+		return 0
+	}
 
-    if total == 0 {
-        return 0
-    }
+	durations := make([]time.Duration, len(samples))
+	for i, sample := range samples {
+		durations[i] = sample.Duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
 
-    return float64(successful) / float64(total) * 100
+	idx := int(p/100*float64(len(durations))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(durations) {
+		idx = len(durations) - 1
+	}
+	return durations[idx]
 }
 
+// PruneOldMetrics drops ring samples older than retention. It runs
+// automatically from a background goroutine started by NewMonitor, but
+// remains exported so callers can force an off-cycle prune.
 func (m *Monitor) PruneOldMetrics(retention time.Duration) {
-    m.mu.Lock()
-    defer m.mu.Unlock()
+	cutoff := time.Now().Add(-retention)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.ops {
+		kept := s.ring.snapshot()
+		fresh := kept[:0]
+		for _, sample := range kept {
+			if sample.Timestamp.After(cutoff) {
+				fresh = append(fresh, sample)
+			}
+		}
+		s.ring = &sampleRing{}
+		for _, sample := range fresh {
+			s.ring.add(sample)
+		}
+	}
+}
+
+// MetricsHandler serves the Monitor's per-operation histograms and
+// counters in Prometheus text exposition format.
+func (m *Monitor) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		var b strings.Builder
+		b.WriteString("# HELP sniper_operation_duration_seconds Operation latency in seconds.\n")
+		b.WriteString("# TYPE sniper_operation_duration_seconds histogram\n")
+		for opType, s := range m.ops {
+			h := s.hist
+			var cumulative uint64
+			for i, le := range latencyBuckets {
+				cumulative += h.bucketCounts[i]
+				fmt.Fprintf(&b, "sniper_operation_duration_seconds_bucket{operation=%q,le=%q} %d\n", opType, fmt.Sprintf("%g", le), cumulative)
+			}
+			fmt.Fprintf(&b, "sniper_operation_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", opType, h.count)
+			fmt.Fprintf(&b, "sniper_operation_duration_seconds_sum{operation=%q} %g\n", opType, h.sumSeconds)
+			fmt.Fprintf(&b, "sniper_operation_duration_seconds_count{operation=%q} %d\n", opType, h.count)
+		}
+
+		b.WriteString("# HELP sniper_operation_success_total Successful operations.\n")
+		b.WriteString("# TYPE sniper_operation_success_total counter\n")
+		for opType, s := range m.ops {
+			fmt.Fprintf(&b, "sniper_operation_success_total{operation=%q} %d\n", opType, s.hist.successCount)
+		}
+
+		b.WriteString("# HELP sniper_operation_total Total operations observed.\n")
+		b.WriteString("# TYPE sniper_operation_total counter\n")
+		for opType, s := range m.ops {
+			fmt.Fprintf(&b, "sniper_operation_total{operation=%q} %d\n", opType, s.hist.count)
+		}
 
-    cutoff := time.Now().Add(-retention)
-    newMetrics := make([]Metric, 0)
+		b.WriteString("# HELP sniper_operation_ewma_latency_seconds EWMA latency estimate.\n")
+		b.WriteString("# TYPE sniper_operation_ewma_latency_seconds gauge\n")
+		for opType, s := range m.ops {
+			if s.ewmaWarmed {
+				fmt.Fprintf(&b, "sniper_operation_ewma_latency_seconds{operation=%q} %g\n", opType, s.ewma)
+			}
+		}
 
-    for _, metric := range m.metrics {
-        if metric.Timestamp.After(cutoff) {
-            newMetrics = append(newMetrics, metric)
-        }
-    }
+		for name, value := range m.gauges {
+			fmt.Fprintf(&b, "# TYPE %s gauge\n%s %g\n", name, name, value)
+		}
 
-    m.metrics = newMetrics
+		w.Write([]byte(b.String()))
+	})
 }
@@ -0,0 +1,99 @@
+package performance
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sial-ari/solana-token-sniper/internal/logger"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	l, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"), logger.Options{Level: logger.INFO})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// TestEWMALatencyStepChange feeds a flat 50ms stream followed by a step
+// to 200ms and asserts the EWMA estimate converges toward the new level
+// well before the windowed mean would, since the latter is still diluted
+// by the pre-step samples.
+func TestEWMALatencyStepChange(t *testing.T) {
+	s := &opState{}
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 30; i++ {
+		s.observeEWMA(0.05, now)
+		now = now.Add(time.Second)
+	}
+	if got := s.ewma; math.Abs(got-0.05) > 0.001 {
+		t.Fatalf("expected ewma to settle near 0.05 before the step, got %v", got)
+	}
+
+	for i := 0; i < 180; i++ {
+		s.observeEWMA(0.2, now)
+		now = now.Add(time.Second)
+	}
+	if got := s.ewma; math.Abs(got-0.2) > 0.01 {
+		t.Fatalf("expected ewma to converge to 0.2 after 180s at default tau, got %v", got)
+	}
+}
+
+// TestEWMALatencySinusoidal feeds a sinusoidal latency stream and
+// asserts the EWMA tracks its mean rather than chasing every individual
+// swing, since a per-op time constant much larger than the sample
+// interval should smooth out high-frequency noise.
+func TestEWMALatencySinusoidal(t *testing.T) {
+	s := &opState{ewmaTau: 30 * time.Second}
+	now := time.Unix(0, 0)
+	const mean = 0.1
+	const amplitude = 0.02
+
+	for i := 0; i < 600; i++ {
+		sample := mean + amplitude*math.Sin(float64(i)*0.5)
+		s.observeEWMA(sample, now)
+		now = now.Add(time.Second)
+	}
+
+	if got := s.ewma; math.Abs(got-mean) > amplitude {
+		t.Fatalf("expected ewma to track the sinusoid's mean %v within +/-%v, got %v", mean, amplitude, got)
+	}
+}
+
+// TestGetEWMALatencyIgnoresFailures asserts a failed operation doesn't
+// drag the EWMA toward its (likely meaningless) duration, mirroring
+// GetAverageLatency's existing success-only semantics.
+func TestGetEWMALatencyIgnoresFailures(t *testing.T) {
+	l := newTestLogger(t)
+	m := NewMonitor(l, time.Hour)
+	defer m.Close()
+
+	m.RecordMetric(OpJupiterSwap, 50*time.Millisecond, true)
+	m.RecordMetric(OpJupiterSwap, 5*time.Second, false)
+
+	got := m.GetEWMALatency(OpJupiterSwap)
+	if got != 50*time.Millisecond {
+		t.Fatalf("expected failed sample to be excluded from the EWMA, got %v", got)
+	}
+}
+
+// TestEstimatedETA asserts the ETA helper is simply queueDepth scaled by
+// the current EWMA latency.
+func TestEstimatedETA(t *testing.T) {
+	l := newTestLogger(t)
+	m := NewMonitor(l, time.Hour)
+	defer m.Close()
+
+	m.RecordMetric(OpJupiterSwap, 100*time.Millisecond, true)
+
+	got := m.EstimatedETA(OpJupiterSwap, 4)
+	want := 400 * time.Millisecond
+	if got != want {
+		t.Fatalf("expected ETA %v for queue depth 4, got %v", want, got)
+	}
+}
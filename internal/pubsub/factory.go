@@ -0,0 +1,10 @@
+package pubsub
+
+// NewBus returns a NATS-backed bus when url is set, otherwise an
+// in-process MemoryBus
+func NewBus(url string) (Bus, error) {
+    if url == "" {
+        return NewMemoryBus(), nil
+    }
+    return NewNATSBus(url)
+}
@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+    "sync"
+    "time"
+)
+
+// MemoryBus is an in-process, fan-out pub/sub bus. It's the default Bus
+// implementation: a single sniper instance talking to itself needs no
+// external broker.
+type MemoryBus struct {
+    mutex       sync.RWMutex
+    subscribers map[string][]chan Event
+    closed      bool
+}
+
+// NewMemoryBus creates a new in-process pub/sub bus
+func NewMemoryBus() *MemoryBus {
+    return &MemoryBus{
+        subscribers: make(map[string][]chan Event),
+    }
+}
+
+// Publish delivers payload to every current subscriber of topic. Delivery
+// is best-effort and non-blocking: a subscriber that isn't keeping up with
+// its channel buffer will miss the event rather than stall the publisher.
+func (b *MemoryBus) Publish(topic string, payload interface{}) error {
+    event := Event{Topic: topic, Payload: payload, Timestamp: time.Now()}
+
+    b.mutex.RLock()
+    defer b.mutex.RUnlock()
+
+    for _, ch := range b.subscribers[topic] {
+        select {
+        case ch <- event:
+        default:
+        }
+    }
+
+    return nil
+}
+
+// Subscribe returns a channel of events for topic and an unsubscribe func
+func (b *MemoryBus) Subscribe(topic string) (<-chan Event, func(), error) {
+    ch := make(chan Event, 32)
+
+    b.mutex.Lock()
+    b.subscribers[topic] = append(b.subscribers[topic], ch)
+    b.mutex.Unlock()
+
+    unsubscribe := func() {
+        b.mutex.Lock()
+        defer b.mutex.Unlock()
+
+        subs := b.subscribers[topic]
+        for i, existing := range subs {
+            if existing == ch {
+                b.subscribers[topic] = append(subs[:i], subs[i+1:]...)
+                close(ch)
+                break
+            }
+        }
+    }
+
+    return ch, unsubscribe, nil
+}
+
+// Close shuts down the bus and closes every subscriber channel
+func (b *MemoryBus) Close() error {
+    b.mutex.Lock()
+    defer b.mutex.Unlock()
+
+    if b.closed {
+        return nil
+    }
+    b.closed = true
+
+    for _, channels := range b.subscribers {
+        for _, ch := range channels {
+            close(ch)
+        }
+    }
+    b.subscribers = make(map[string][]chan Event)
+
+    return nil
+}
@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/nats-io/nats.go"
+)
+
+// NATSBus is a Bus backed by a NATS connection, letting multiple sniper
+// instances share a single WebSocket feed via NATS fan-out. Payloads cross
+// the wire as JSON, so subscribers receive json.RawMessage and must decode
+// into the concrete type they expect.
+type NATSBus struct {
+    conn *nats.Conn
+}
+
+// NewNATSBus connects to the given NATS server URL
+func NewNATSBus(url string) (*NATSBus, error) {
+    conn, err := nats.Connect(url)
+    if err != nil {
+        return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+    }
+    return &NATSBus{conn: conn}, nil
+}
+
+// Publish JSON-encodes payload and publishes it on the NATS subject
+// matching topic
+func (b *NATSBus) Publish(topic string, payload interface{}) error {
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return fmt.Errorf("failed to marshal payload for topic %s: %w", topic, err)
+    }
+    return b.conn.Publish(topic, data)
+}
+
+// Subscribe returns a channel of events for topic. Event.Payload is a
+// json.RawMessage; callers unmarshal it into the type they expect.
+func (b *NATSBus) Subscribe(topic string) (<-chan Event, func(), error) {
+    out := make(chan Event, 32)
+
+    sub, err := b.conn.Subscribe(topic, func(msg *nats.Msg) {
+        event := Event{
+            Topic:     topic,
+            Payload:   json.RawMessage(msg.Data),
+            Timestamp: time.Now(),
+        }
+        select {
+        case out <- event:
+        default:
+        }
+    })
+    if err != nil {
+        close(out)
+        return nil, nil, fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+    }
+
+    unsubscribe := func() {
+        _ = sub.Unsubscribe()
+        close(out)
+    }
+
+    return out, unsubscribe, nil
+}
+
+// Close drains and closes the underlying NATS connection
+func (b *NATSBus) Close() error {
+    b.conn.Drain()
+    return nil
+}
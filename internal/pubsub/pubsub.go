@@ -0,0 +1,44 @@
+// Package pubsub decouples token-event producers (the WebSocket scanner)
+// from consumers (price monitors, notifiers, external sinks) behind a
+// small topic-based interface. The in-process bus is the default; a
+// NATS-backed bus can be selected via configuration so multiple sniper
+// instances can share a single WebSocket feed.
+package pubsub
+
+import "time"
+
+// Well-known topics published by the sniper pipeline
+const (
+    TopicNewTokens      = "tokens.new"
+    TopicPricesUpdated  = "prices.updated"
+    TopicPLUpdated      = "pl.updated"
+    TopicStrategyEvents = "strategy.events"
+)
+
+// Event is a single message delivered on a topic. Payload is JSON-encoded
+// by bus implementations that cross a process boundary (e.g. NATS), so it
+// must be a value that round-trips through encoding/json.
+type Event struct {
+    Topic     string      `json:"topic"`
+    Payload   interface{} `json:"payload"`
+    Timestamp time.Time   `json:"timestamp"`
+}
+
+// Publisher publishes payloads to a topic
+type Publisher interface {
+    Publish(topic string, payload interface{}) error
+}
+
+// Subscriber delivers events published to a topic. The returned channel is
+// closed, and the unsubscribe func becomes a no-op, once Close is called
+// on the underlying bus or the subscription is cancelled.
+type Subscriber interface {
+    Subscribe(topic string) (<-chan Event, func(), error)
+}
+
+// Bus is a Publisher and Subscriber pair plus lifecycle management
+type Bus interface {
+    Publisher
+    Subscriber
+    Close() error
+}
@@ -0,0 +1,272 @@
+package pyth
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "log"
+    "math"
+    "sync"
+    "time"
+
+    "github.com/gorilla/websocket"
+    "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+)
+
+// pythMagic identifies a Pyth v2 price account (little-endian "pyth" magic number)
+const pythMagic = 0xa1b2c3d4
+
+// PriceUpdate is a decoded reading from a Pyth price account
+type PriceUpdate struct {
+    Mint        string
+    Price       float64
+    Confidence  float64
+    Expo        int32
+    PublishSlot uint64
+}
+
+// Client subscribes to Pyth price account updates over the Solana
+// accountSubscribe WebSocket RPC and decodes the Pyth price/confidence/expo
+// fields out of the account data.
+type Client struct {
+    conn      *websocket.Conn
+    url       string
+    db        *db.Database
+    mutex     sync.Mutex
+    nextID    int
+    // feeds maps a Pyth price account address to the mint it prices
+    feeds     map[string]string
+    // pending maps an in-flight accountSubscribe request's JSON-RPC id to
+    // the price account it was sent for, so the ack can be correlated to
+    // the right account instead of guessed at
+    pending   map[int64]string
+    // subs maps the JSON-RPC subscription id returned by accountSubscribe
+    // back to the price account address so notifications can be matched
+    subs      map[int64]string
+    done      chan struct{}
+}
+
+// NewClient creates a new Pyth client that will talk to the given Solana
+// WebSocket RPC endpoint
+func NewClient(url string, database *db.Database) *Client {
+    return &Client{
+        url:     url,
+        db:      database,
+        feeds:   make(map[string]string),
+        pending: make(map[int64]string),
+        subs:    make(map[int64]string),
+        done:    make(chan struct{}),
+    }
+}
+
+// Connect establishes the WebSocket connection used for account subscriptions
+func (c *Client) Connect(ctx context.Context) error {
+    dialer := websocket.DefaultDialer
+    conn, _, err := dialer.DialContext(ctx, c.url, nil)
+    if err != nil {
+        return fmt.Errorf("failed to connect to Pyth websocket: %w", err)
+    }
+
+    c.mutex.Lock()
+    c.conn = conn
+    c.mutex.Unlock()
+
+    go c.handleMessages(ctx)
+
+    return nil
+}
+
+// SubscribePriceAccount subscribes to updates for a Pyth price account and
+// associates it with the given mint so future updates can be attributed
+func (c *Client) SubscribePriceAccount(mint, priceAccount string) error {
+    c.mutex.Lock()
+    c.feeds[priceAccount] = mint
+    c.nextID++
+    id := c.nextID
+    c.pending[int64(id)] = priceAccount
+    conn := c.conn
+    c.mutex.Unlock()
+
+    if conn == nil {
+        return fmt.Errorf("pyth client is not connected")
+    }
+
+    req := map[string]interface{}{
+        "jsonrpc": "2.0",
+        "id":      id,
+        "method":  "accountSubscribe",
+        "params": []interface{}{
+            priceAccount,
+            map[string]string{
+                "encoding":   "base64",
+                "commitment": "confirmed",
+            },
+        },
+    }
+
+    message, err := json.Marshal(req)
+    if err != nil {
+        c.mutex.Lock()
+        delete(c.pending, int64(id))
+        c.mutex.Unlock()
+        return fmt.Errorf("failed to marshal accountSubscribe request: %w", err)
+    }
+
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+        delete(c.pending, int64(id))
+        return err
+    }
+    return nil
+}
+
+// subscribeAck is the RPC response to accountSubscribe, carrying the
+// subscription id that subsequent notifications reference
+type subscribeAck struct {
+    ID     int64 `json:"id"`
+    Result int64 `json:"result"`
+}
+
+// accountNotification is a Solana accountSubscribe notification envelope
+type accountNotification struct {
+    Method string `json:"method"`
+    Params struct {
+        Subscription int64 `json:"subscription"`
+        Result       struct {
+            Value struct {
+                Data []string `json:"data"`
+            } `json:"value"`
+        } `json:"result"`
+    } `json:"params"`
+}
+
+func (c *Client) handleMessages(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-c.done:
+            return
+        default:
+            _, message, err := c.conn.ReadMessage()
+            if err != nil {
+                log.Printf("pyth: error reading message: %v", err)
+                return
+            }
+
+            c.processMessage(message)
+        }
+    }
+}
+
+func (c *Client) processMessage(message []byte) {
+    var ack subscribeAck
+    if err := json.Unmarshal(message, &ack); err == nil && ack.Result != 0 {
+        // This is the initial accountSubscribe acknowledgement; ack.ID is
+        // the JSON-RPC request id we sent it under, which pending
+        // resolves back to the exact price account that request was for.
+        c.mutex.Lock()
+        if account, ok := c.pending[ack.ID]; ok {
+            c.subs[ack.Result] = account
+            delete(c.pending, ack.ID)
+        }
+        c.mutex.Unlock()
+        return
+    }
+
+    var note accountNotification
+    if err := json.Unmarshal(message, &note); err != nil || note.Method != "accountNotification" {
+        return
+    }
+
+    if len(note.Params.Result.Value.Data) == 0 {
+        return
+    }
+
+    data, err := base64.StdEncoding.DecodeString(note.Params.Result.Value.Data[0])
+    if err != nil {
+        log.Printf("pyth: failed to decode account data: %v", err)
+        return
+    }
+
+    update, err := decodePriceAccount(data)
+    if err != nil {
+        log.Printf("pyth: failed to decode price account: %v", err)
+        return
+    }
+
+    c.mutex.Lock()
+    account, ok := c.subs[note.Params.Subscription]
+    mint := c.feeds[account]
+    c.mutex.Unlock()
+    if !ok || mint == "" {
+        return
+    }
+    update.Mint = mint
+
+    oracle := &models.OraclePrice{
+        Mint:        update.Mint,
+        Price:       update.Price,
+        Confidence:  update.Confidence,
+        Expo:        update.Expo,
+        PublishSlot: update.PublishSlot,
+        Source:      "pyth",
+        Timestamp:   time.Now(),
+    }
+
+    if err := c.db.SaveOraclePrice(oracle); err != nil {
+        log.Printf("pyth: failed to save oracle price for %s: %v", mint, err)
+    }
+}
+
+// decodePriceAccount parses the subset of the Pyth v2 Price account layout
+// needed for a spot read: magic, exponent, and the current aggregate
+// price/confidence/slot fields. See https://docs.pyth.network/price-feeds/account-structure
+func decodePriceAccount(data []byte) (PriceUpdate, error) {
+    const (
+        offMagic    = 0
+        offExpo     = 20
+        offAggPrice = 208
+        offAggConf  = 216
+        offAggSlot  = 224
+    )
+
+    if len(data) < offAggSlot+8 {
+        return PriceUpdate{}, fmt.Errorf("price account data too short: %d bytes", len(data))
+    }
+
+    magic := binary.LittleEndian.Uint32(data[offMagic:])
+    if magic != pythMagic {
+        return PriceUpdate{}, fmt.Errorf("unexpected magic number: %#x", magic)
+    }
+
+    expo := int32(binary.LittleEndian.Uint32(data[offExpo:]))
+    rawPrice := int64(binary.LittleEndian.Uint64(data[offAggPrice:]))
+    rawConf := binary.LittleEndian.Uint64(data[offAggConf:])
+    slot := binary.LittleEndian.Uint64(data[offAggSlot:])
+
+    scale := math.Pow10(int(expo))
+
+    return PriceUpdate{
+        Price:       float64(rawPrice) * scale,
+        Confidence:  float64(rawConf) * scale,
+        Expo:        expo,
+        PublishSlot: slot,
+    }, nil
+}
+
+// Close shuts down the Pyth WebSocket connection
+func (c *Client) Close() error {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+
+    close(c.done)
+    if c.conn != nil {
+        return c.conn.Close()
+    }
+    return nil
+}
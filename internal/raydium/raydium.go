@@ -0,0 +1,202 @@
+// Package raydium prices swaps directly against a Raydium constant-product
+// AMM pool, for tokens Jupiter's aggregator hasn't indexed a route for yet -
+// typically a pump.fun mint in its first few seconds of trading.
+package raydium
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/sial-ari/solana-token-sniper/internal/router"
+)
+
+// solMint is wrapped SOL's mint address; every pool registered here trades
+// a token against it.
+const solMint = "So11111111111111111111111111111111111111112"
+
+// Pool identifies the two SPL token vault accounts backing a Raydium pool
+// for mint against SOL.
+type Pool struct {
+    // BaseVault holds the token's reserve, QuoteVault holds the SOL reserve.
+    BaseVault  string
+    QuoteVault string
+}
+
+// Backend prices swaps against Raydium pools using their vault reserves and
+// the constant-product formula. It never submits a transaction itself -
+// ExecuteSwap returns an error, the same as this codebase's other backends
+// do for anything beyond building/pricing a route.
+type Backend struct {
+    rpcURL     string
+    httpClient *http.Client
+
+    mutex sync.RWMutex
+    pools map[string]Pool // mint -> pool
+}
+
+// NewBackend creates a Raydium backend that reads vault balances from the
+// given Solana RPC endpoint (cfg.SolanaRPCURL).
+func NewBackend(rpcURL string) *Backend {
+    return &Backend{
+        rpcURL:     rpcURL,
+        httpClient: &http.Client{Timeout: 10 * time.Second},
+        pools:      make(map[string]Pool),
+    }
+}
+
+// RegisterPool associates mint with the Raydium pool that trades it against
+// SOL, so GetQuote can price it. Unregistered mints return an error from
+// GetQuote - this backend only ever routes pools it's explicitly told about.
+func (b *Backend) RegisterPool(mint string, pool Pool) {
+    b.mutex.Lock()
+    defer b.mutex.Unlock()
+    b.pools[mint] = pool
+}
+
+func (b *Backend) Name() string { return "raydium" }
+
+// GetQuote prices inputMint -> outputMint against the registered pool's
+// current vault reserves using the constant-product (x*y=k) formula. One of
+// inputMint/outputMint must be SOL, since every pool registered here trades
+// against it.
+func (b *Backend) GetQuote(ctx context.Context, inputMint, outputMint string, amount float64, slippageBps int) (*router.Quote, error) {
+    mint, sellingToken, err := poolMint(inputMint, outputMint)
+    if err != nil {
+        return nil, err
+    }
+
+    pool, ok := b.pool(mint)
+    if !ok {
+        return nil, fmt.Errorf("raydium: no pool registered for %s", mint)
+    }
+
+    tokenReserve, err := b.vaultBalance(ctx, pool.BaseVault)
+    if err != nil {
+        return nil, fmt.Errorf("raydium: failed to read token reserve: %w", err)
+    }
+    solReserve, err := b.vaultBalance(ctx, pool.QuoteVault)
+    if err != nil {
+        return nil, fmt.Errorf("raydium: failed to read SOL reserve: %w", err)
+    }
+
+    // sellingToken is true when inputMint is the token (selling it for SOL);
+    // otherwise we're buying it with SOL.
+    inReserve, outReserve := solReserve, tokenReserve
+    if sellingToken {
+        inReserve, outReserve = tokenReserve, solReserve
+    }
+
+    outAmount := constantProductOut(inReserve, outReserve, amount)
+    price := outAmount / amount
+    minimumOutAmount := outAmount * (1 - float64(slippageBps)/10000)
+
+    return &router.Quote{
+        InputMint:        inputMint,
+        OutputMint:       outputMint,
+        InAmount:         amount,
+        OutAmount:        outAmount,
+        Price:            price,
+        PriceImpactPct:   priceImpactPct(inReserve, amount),
+        MinimumOutAmount: minimumOutAmount,
+        Source:           "raydium",
+    }, nil
+}
+
+// ExecuteSwap isn't implemented: unlike Jupiter's API, building and signing
+// a Raydium swap instruction directly requires a Solana transaction
+// builder this codebase doesn't have. Quoting still lets GetQuote fall back
+// to Raydium for tokens Jupiter has no route for.
+func (b *Backend) ExecuteSwap(ctx context.Context, quote *router.Quote, userPubKey string) (*router.Signature, error) {
+    return nil, fmt.Errorf("raydium: direct swap execution is not implemented")
+}
+
+func (b *Backend) pool(mint string) (Pool, bool) {
+    b.mutex.RLock()
+    defer b.mutex.RUnlock()
+    pool, ok := b.pools[mint]
+    return pool, ok
+}
+
+// poolMint returns the non-SOL mint of the pair and whether inputMint is
+// the token being sold, or an error if neither side is SOL.
+func poolMint(inputMint, outputMint string) (mint string, sellingToken bool, err error) {
+    switch {
+    case inputMint == solMint:
+        return outputMint, false, nil
+    case outputMint == solMint:
+        return inputMint, true, nil
+    default:
+        return "", false, fmt.Errorf("raydium: one side of the swap must be SOL")
+    }
+}
+
+// constantProductOut applies the constant-product formula x*y=k: selling
+// amount of the input reserve's asset yields this much of the output
+// reserve's asset, before slippage tolerance.
+func constantProductOut(inReserve, outReserve, amount float64) float64 {
+    k := inReserve * outReserve
+    return outReserve - k/(inReserve+amount)
+}
+
+// priceImpactPct estimates the price impact of trading amount against a
+// pool with the given reserve on the input side.
+func priceImpactPct(inReserve, amount float64) float64 {
+    if inReserve <= 0 {
+        return 100
+    }
+    return (amount / (inReserve + amount)) * 100
+}
+
+type tokenBalanceResponse struct {
+    Result struct {
+        Value struct {
+            UiAmount float64 `json:"uiAmount"`
+        } `json:"value"`
+    } `json:"result"`
+    Error *struct {
+        Message string `json:"message"`
+    } `json:"error"`
+}
+
+// vaultBalance reads vault's current SPL token balance via the Solana RPC
+// getTokenAccountBalance method.
+func (b *Backend) vaultBalance(ctx context.Context, vault string) (float64, error) {
+    req := map[string]interface{}{
+        "jsonrpc": "2.0",
+        "id":      1,
+        "method":  "getTokenAccountBalance",
+        "params":  []interface{}{vault},
+    }
+
+    body, err := json.Marshal(req)
+    if err != nil {
+        return 0, fmt.Errorf("failed to marshal RPC request: %w", err)
+    }
+
+    httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.rpcURL, bytes.NewReader(body))
+    if err != nil {
+        return 0, fmt.Errorf("failed to build RPC request: %w", err)
+    }
+    httpReq.Header.Set("Content-Type", "application/json")
+
+    resp, err := b.httpClient.Do(httpReq)
+    if err != nil {
+        return 0, fmt.Errorf("RPC request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    var result tokenBalanceResponse
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return 0, fmt.Errorf("failed to decode RPC response: %w", err)
+    }
+    if result.Error != nil {
+        return 0, fmt.Errorf("RPC error: %s", result.Error.Message)
+    }
+
+    return result.Result.Value.UiAmount, nil
+}
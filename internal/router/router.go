@@ -0,0 +1,186 @@
+// Package router abstracts price discovery and swap execution across
+// multiple Solana DEX integrations - Jupiter's aggregator, direct AMM
+// pools - behind one Quoter/Swapper interface, so callers don't need to
+// know which backend ultimately filled a quote.
+package router
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+)
+
+// Quote is a backend-agnostic price quote for swapping InputMint into
+// OutputMint. Amounts are in the same unit the caller passed to GetQuote
+// (typically lamports for InAmount, the output token's smallest unit for
+// OutAmount), so callers comparing quotes across backends don't need to
+// know the input unit to compare them.
+type Quote struct {
+    InputMint        string
+    OutputMint       string
+    InAmount         float64
+    OutAmount        float64
+    Price            float64 // OutputMint per InputMint
+    PriceImpactPct   float64
+    MinimumOutAmount float64
+    Source           string // name of the backend that produced this quote
+}
+
+// Depth describes the liquidity backing a mint on one backend, used to
+// judge whether a route is worth taking before committing to it.
+type Depth struct {
+    Mint         string
+    BaseReserve  float64
+    QuoteReserve float64
+    Source       string
+}
+
+// Signature identifies the outcome of ExecuteSwap. Backends that don't
+// submit the transaction themselves (Jupiter's API only builds one) put
+// the unsigned, base64-encoded transaction here instead of an on-chain
+// signature - callers that need it on-chain still have to sign and send it.
+type Signature struct {
+    TxID   string
+    Source string
+}
+
+// Quoter can price a swap without executing it.
+type Quoter interface {
+    Name() string
+    GetQuote(ctx context.Context, inputMint, outputMint string, amount float64, slippageBps int) (*Quote, error)
+}
+
+// Swapper executes a swap, typically one just priced by the same
+// backend's Quoter.
+type Swapper interface {
+    ExecuteSwap(ctx context.Context, quote *Quote, userPubKey string) (*Signature, error)
+}
+
+// DepthProvider exposes pool liquidity depth for a mint.
+type DepthProvider interface {
+    GetDepth(ctx context.Context, mint string) (*Depth, error)
+}
+
+// Backend is a single DEX integration capable of quoting and swapping.
+type Backend interface {
+    Quoter
+    Swapper
+}
+
+// NoDepth is embedded by backends with no meaningful per-mint depth of
+// their own (e.g. an aggregator that routes across other venues' pools),
+// so they satisfy DepthProvider without each duplicating this error.
+type NoDepth struct{ Name string }
+
+func (n NoDepth) GetDepth(ctx context.Context, mint string) (*Depth, error) {
+    return nil, fmt.Errorf("%s: depth not available", n.Name)
+}
+
+// Policy selects how Router.BestQuote picks among the quotes its backends
+// return for the same swap.
+type Policy int
+
+const (
+    // PolicyBestPrice picks the quote with the highest OutAmount.
+    PolicyBestPrice Policy = iota
+    // PolicyLowestSlippage picks the quote with the lowest PriceImpactPct.
+    PolicyLowestSlippage
+)
+
+// Router holds a registry of Backends and picks among the quotes they
+// return for a given swap.
+type Router struct {
+    mutex    sync.RWMutex
+    backends []Backend
+}
+
+// New returns an empty Router; Register backends onto it before use.
+func New() *Router {
+    return &Router{}
+}
+
+// Register adds a backend to the registry. Order doesn't matter: BestQuote
+// queries every registered backend and compares their results by policy.
+func (r *Router) Register(b Backend) {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+    r.backends = append(r.backends, b)
+}
+
+// Backends returns the currently registered backends.
+func (r *Router) Backends() []Backend {
+    r.mutex.RLock()
+    defer r.mutex.RUnlock()
+    return append([]Backend{}, r.backends...)
+}
+
+// BestQuote queries every registered backend concurrently and returns the
+// quote chosen by policy. A backend erroring - e.g. Jupiter has no route
+// for a brand-new pumpportal token - doesn't fail the call as long as at
+// least one other backend succeeds, which is what lets quoting fall back
+// across routes instead of failing outright.
+func (r *Router) BestQuote(ctx context.Context, inputMint, outputMint string, amount float64, slippageBps int, policy Policy) (*Quote, error) {
+    backends := r.Backends()
+    if len(backends) == 0 {
+        return nil, fmt.Errorf("no backends registered")
+    }
+
+    type result struct {
+        quote *Quote
+        err   error
+    }
+
+    results := make(chan result, len(backends))
+    for _, b := range backends {
+        go func(b Backend) {
+            quote, err := b.GetQuote(ctx, inputMint, outputMint, amount, slippageBps)
+            results <- result{quote: quote, err: err}
+        }(b)
+    }
+
+    var quotes []*Quote
+    var errs []error
+    for i := 0; i < len(backends); i++ {
+        res := <-results
+        if res.err != nil {
+            errs = append(errs, res.err)
+            continue
+        }
+        quotes = append(quotes, res.quote)
+    }
+
+    if len(quotes) == 0 {
+        return nil, fmt.Errorf("no backend could quote %s -> %s: %w", inputMint, outputMint, errors.Join(errs...))
+    }
+
+    return pick(quotes, policy), nil
+}
+
+// ExecuteSwap re-dispatches to the backend named by quote.Source, so the
+// swap is executed against the same route it was priced on.
+func (r *Router) ExecuteSwap(ctx context.Context, quote *Quote, userPubKey string) (*Signature, error) {
+    for _, b := range r.Backends() {
+        if b.Name() == quote.Source {
+            return b.ExecuteSwap(ctx, quote, userPubKey)
+        }
+    }
+    return nil, fmt.Errorf("no registered backend named %q", quote.Source)
+}
+
+func pick(quotes []*Quote, policy Policy) *Quote {
+    best := quotes[0]
+    for _, q := range quotes[1:] {
+        switch policy {
+        case PolicyLowestSlippage:
+            if q.PriceImpactPct < best.PriceImpactPct {
+                best = q
+            }
+        default: // PolicyBestPrice
+            if q.OutAmount > best.OutAmount {
+                best = q
+            }
+        }
+    }
+    return best
+}
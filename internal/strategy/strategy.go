@@ -0,0 +1,342 @@
+// Package strategy evaluates declarative exit rules against price ticks
+// and fires a Trader's ExecuteSwap back into SOL when a take-profit,
+// stop-loss, trailing-stop, or time-based exit triggers. It's the natural
+// consumer of the P&L updates db.Database.UpdateProfitLoss already
+// computes but nothing previously acted on.
+package strategy
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/pubsub"
+)
+
+// Trader executes the exit swaps an Engine decides on. jupiter.Client
+// satisfies it for live trading; internal/backtest substitutes a mock
+// that records fills against historical prices instead of submitting
+// them, so the same rule logic can run against replayed history.
+type Trader interface {
+    ExecuteSwap(ctx context.Context, mint string, solAmount float64, userPubKey string) error
+}
+
+// TakeProfitLevel sells SellPct (0-1) of the *original* position once
+// price is up TriggerPct from entry. Config should list levels ascending
+// by TriggerPct - e.g. sell 50% at +100%, the remainder at +300% - since
+// evaluate fires them in order and stops at the first one not yet met.
+type TakeProfitLevel struct {
+    TriggerPct float64
+    SellPct    float64
+}
+
+// TimeExitRule fully exits a position that hasn't gained MinGainPct
+// within Window of entry.
+type TimeExitRule struct {
+    Window     time.Duration
+    MinGainPct float64
+}
+
+// Rules is a declarative set of exit conditions for one token, or the
+// engine-wide default. Zero-valued fields disable that rule: StopLossPct
+// and TrailingStopPct of 0 never trigger, a nil TimeExit never triggers,
+// and an empty TakeProfit never partially exits.
+type Rules struct {
+    TakeProfit      []TakeProfitLevel
+    StopLossPct     float64 // full exit once price is down this % from entry
+    TrailingStopPct float64 // full exit once price is down this % from the running max
+    TimeExit        *TimeExitRule
+}
+
+// Config maps mints to the Rules that govern them, falling back to
+// Default for any mint without a specific entry.
+type Config struct {
+    Default  Rules
+    PerToken map[string]Rules
+}
+
+func (c Config) rulesFor(mint string) Rules {
+    if r, ok := c.PerToken[mint]; ok {
+        return r
+    }
+    return c.Default
+}
+
+// EventType identifies a strategy lifecycle event.
+type EventType string
+
+const (
+    EventEntry       EventType = "entry"
+    EventPartialExit EventType = "partial_exit"
+    EventStoppedOut  EventType = "stopped_out"
+)
+
+// Event is published on pubsub.TopicStrategyEvents whenever a position is
+// opened, partially exited, or fully closed, so the Telegram bot (or any
+// other subscriber) can notify on it without polling the positions table.
+type Event struct {
+    Type      EventType `json:"type"`
+    Mint      string    `json:"mint"`
+    Price     float64   `json:"price"`
+    SolAmount float64   `json:"solAmount"` // SOL committed on entry, realized on exit
+    Reason    string    `json:"reason"`
+    Timestamp time.Time `json:"timestamp"`
+}
+
+// Engine evaluates Rules against price ticks and fires exits through a
+// Trader. It subscribes to the same pubsub.TopicPricesUpdated events
+// jupiter.Client.StartPriceMonitoring publishes on its own ticker, so
+// positions are re-evaluated on that same cadence instead of running a
+// second ticker.
+type Engine struct {
+    db         *db.Database
+    trader     Trader
+    bus        pubsub.Bus
+    config     Config
+    userPubKey string
+    dryRun     bool
+
+    mutex     sync.Mutex
+    positions map[string]*models.Position
+
+    done   chan struct{}
+    logger *logger.Logger
+}
+
+// NewEngine creates an Engine and loads any open positions persisted from
+// a previous run, so a restart resumes managing them instead of losing
+// track mid-trade. dryRun logs exits instead of executing them, for paper
+// trading against live prices.
+func NewEngine(database *db.Database, trader Trader, bus pubsub.Bus, config Config, userPubKey string, dryRun bool) (*Engine, error) {
+    open, err := database.GetOpenPositions()
+    if err != nil {
+        return nil, fmt.Errorf("failed to load open positions: %w", err)
+    }
+
+    positions := make(map[string]*models.Position, len(open))
+    for i := range open {
+        positions[open[i].Mint] = &open[i]
+    }
+
+    return &Engine{
+        db:         database,
+        trader:     trader,
+        bus:        bus,
+        config:     config,
+        userPubKey: userPubKey,
+        dryRun:     dryRun,
+        positions:  positions,
+        done:       make(chan struct{}),
+        logger:     logger.FromContext(context.Background()).Named("strategy"),
+    }, nil
+}
+
+// Enter opens a new tracked position for mint at entryPrice, having spent
+// solAmount SOL to buy it at at. Call it right after a buy swap executes
+// (e.g. from the Telegram bot's /swap handler, passing time.Now()) so the
+// engine starts managing its exit.
+func (e *Engine) Enter(mint string, entryPrice, solAmount float64, at time.Time) error {
+    pos := &models.Position{
+        Mint:           mint,
+        EntryPrice:     entryPrice,
+        EntrySolAmount: solAmount,
+        EntryTime:      at,
+        MaxPrice:       entryPrice,
+        RemainingPct:   1,
+    }
+
+    e.mutex.Lock()
+    e.positions[mint] = pos
+    e.mutex.Unlock()
+
+    if err := e.db.SavePosition(pos); err != nil {
+        return fmt.Errorf("failed to persist position: %w", err)
+    }
+
+    e.publish(Event{
+        Type:      EventEntry,
+        Mint:      mint,
+        Price:     entryPrice,
+        SolAmount: solAmount,
+        Timestamp: pos.EntryTime,
+    })
+    return nil
+}
+
+// Start subscribes to pubsub.TopicPricesUpdated and evaluates every
+// tracked position's rules as ticks arrive, until ctx is cancelled or
+// Close is called.
+func (e *Engine) Start(ctx context.Context) error {
+    if e.bus == nil {
+        return fmt.Errorf("strategy: engine has no pubsub bus configured")
+    }
+
+    e.logger = logger.FromContext(ctx).Named("strategy")
+
+    events, unsubscribe, err := e.bus.Subscribe(pubsub.TopicPricesUpdated)
+    if err != nil {
+        return fmt.Errorf("failed to subscribe to price updates: %w", err)
+    }
+    defer unsubscribe()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-e.done:
+            return nil
+        case event, ok := <-events:
+            if !ok {
+                return nil
+            }
+            price, ok := event.Payload.(*models.TokenPrice)
+            if !ok {
+                continue
+            }
+            if err := e.Evaluate(ctx, price.Mint, price.Price, price.Timestamp); err != nil {
+                e.logger.Error("failed to evaluate rules", logger.F("mint", price.Mint), logger.F("error", err))
+            }
+        }
+    }
+}
+
+// Evaluate checks mint's tracked position against its Rules at price as
+// observed at at, firing whichever exit - if any - triggers first:
+// stop-loss, trailing stop, time exit, then any take-profit levels now in
+// range. Start calls this with each tick's own timestamp; internal/backtest
+// calls it directly to replay historical ticks without going through
+// pubsub, so exits land against simulated rather than wall-clock time.
+func (e *Engine) Evaluate(ctx context.Context, mint string, price float64, at time.Time) error {
+    e.mutex.Lock()
+    pos, tracked := e.positions[mint]
+    e.mutex.Unlock()
+    if !tracked || pos.Closed {
+        return nil
+    }
+
+    if price > pos.MaxPrice {
+        pos.MaxPrice = price
+    }
+
+    rules := e.config.rulesFor(mint)
+    gainPct := pctChange(pos.EntryPrice, price)
+
+    if rules.StopLossPct > 0 && gainPct <= -rules.StopLossPct {
+        if err := e.exit(ctx, pos, price, pos.RemainingPct, "stop_loss", at); err != nil {
+            return err
+        }
+        return e.db.SavePosition(pos)
+    }
+
+    if rules.TrailingStopPct > 0 {
+        drawdownPct := pctChange(pos.MaxPrice, price)
+        if drawdownPct <= -rules.TrailingStopPct {
+            if err := e.exit(ctx, pos, price, pos.RemainingPct, "trailing_stop", at); err != nil {
+                return err
+            }
+            return e.db.SavePosition(pos)
+        }
+    }
+
+    if rules.TimeExit != nil && at.Sub(pos.EntryTime) >= rules.TimeExit.Window && gainPct < rules.TimeExit.MinGainPct {
+        if err := e.exit(ctx, pos, price, pos.RemainingPct, "time_exit", at); err != nil {
+            return err
+        }
+        return e.db.SavePosition(pos)
+    }
+
+    for pos.TakeProfitsFired < len(rules.TakeProfit) {
+        level := rules.TakeProfit[pos.TakeProfitsFired]
+        if gainPct < level.TriggerPct {
+            break
+        }
+
+        sellPct := level.SellPct
+        if sellPct > pos.RemainingPct {
+            sellPct = pos.RemainingPct
+        }
+        // TakeProfitsFired is only advanced once the sell actually
+        // succeeds, so a transient ExecuteSwap error leaves this level
+        // eligible to retry on the next tick instead of being silently
+        // skipped forever.
+        if err := e.exit(ctx, pos, price, sellPct, fmt.Sprintf("take_profit_%d", pos.TakeProfitsFired+1), at); err != nil {
+            return err
+        }
+        pos.TakeProfitsFired++
+        if pos.Closed {
+            break
+        }
+    }
+
+    return e.db.SavePosition(pos)
+}
+
+// exit sells sellPct (a fraction of the *original* position) back into
+// SOL, updates pos in place, and emits the matching lifecycle event.
+// Selling off the last of RemainingPct closes the position and emits
+// stopped_out instead of partial_exit, regardless of which rule
+// triggered it.
+func (e *Engine) exit(ctx context.Context, pos *models.Position, price, sellPct float64, reason string, at time.Time) error {
+    if sellPct <= 0 {
+        return nil
+    }
+
+    solAmount := pos.EntrySolAmount * sellPct
+    tokenLogger := e.logger.With(logger.F("mint", pos.Mint), logger.F("reason", reason))
+
+    if e.dryRun {
+        tokenLogger.Info("dry run: would execute exit swap", logger.F("sol_amount", solAmount))
+    } else if err := e.trader.ExecuteSwap(ctx, pos.Mint, solAmount, e.userPubKey); err != nil {
+        return fmt.Errorf("failed to execute exit swap: %w", err)
+    }
+
+    pos.RealizedPL += solAmount * (pctChange(pos.EntryPrice, price) / 100)
+    pos.RemainingPct -= sellPct
+    if pos.RemainingPct <= 0.0001 {
+        pos.RemainingPct = 0
+        pos.Closed = true
+        pos.ClosedAt = at
+    }
+
+    eventType := EventPartialExit
+    if pos.Closed {
+        eventType = EventStoppedOut
+    }
+
+    e.publish(Event{
+        Type:      eventType,
+        Mint:      pos.Mint,
+        Price:     price,
+        SolAmount: solAmount,
+        Reason:    reason,
+        Timestamp: at,
+    })
+    return nil
+}
+
+// publish fans a strategy event out on pubsub.TopicStrategyEvents; it's a
+// no-op if the engine has no bus configured.
+func (e *Engine) publish(event Event) {
+    if e.bus == nil {
+        return
+    }
+    if err := e.bus.Publish(pubsub.TopicStrategyEvents, event); err != nil {
+        e.logger.Error("failed to publish strategy event", logger.F("error", err))
+    }
+}
+
+// Close stops Start's event loop.
+func (e *Engine) Close() {
+    close(e.done)
+}
+
+func pctChange(from, to float64) float64 {
+    if from == 0 {
+        return 0
+    }
+    return (to - from) / from * 100
+}
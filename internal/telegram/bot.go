@@ -2,8 +2,9 @@ package telegram
 
 import (
     "context"
+    "crypto/ed25519"
+    "encoding/base64"
     "fmt"
-    "log"
     "strconv"
     "strings"
     "sync"
@@ -12,16 +13,37 @@ import (
     "github.com/sial-ari/solana-token-sniper/internal/config"
     "github.com/sial-ari/solana-token-sniper/internal/db"
     "github.com/sial-ari/solana-token-sniper/internal/jupiter"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/pubsub"
+    "github.com/sial-ari/solana-token-sniper/internal/strategy"
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// privilegedCommands can drain or reconfigure the bot, so handleMessage
+// requires them to come from an allow-listed chat or to carry a valid
+// signature (see authorize) rather than trusting anyone holding the bot
+// token.
+var privilegedCommands = map[string]bool{
+    "/swap":      true,
+    "/setconfig": true,
+    "/dryrun":    true,
+}
+
 type Bot struct {
     api          *tgbotapi.BotAPI
     db           *db.Database
     jupiter      *jupiter.Client
     config       *config.Config
     allowedUsers map[int64]bool
+    signingKey   ed25519.PublicKey
     mutex        sync.RWMutex
+    logger       *logger.Logger
+
+    // strategy is optional: when set via SetStrategyEngine, a successful
+    // /swap hands the new position off to it so its exit gets managed
+    // automatically instead of requiring a manual sell later.
+    strategy *strategy.Engine
 }
 
 func NewBot(token string, database *db.Database, jupiterClient *jupiter.Client, cfg *config.Config) (*Bot, error) {
@@ -33,16 +55,57 @@ func NewBot(token string, database *db.Database, jupiterClient *jupiter.Client,
     // For development, you might want to enable debugging
     api.Debug = true
 
+    allowedUsers := make(map[int64]bool, len(cfg.AllowedUsers))
+    for _, id := range cfg.AllowedUsers {
+        allowedUsers[id] = true
+    }
+
+    var signingKey ed25519.PublicKey
+    if cfg.SigningPublicKey != "" {
+        decoded, err := base64.StdEncoding.DecodeString(cfg.SigningPublicKey)
+        if err != nil {
+            return nil, fmt.Errorf("invalid signing public key: %w", err)
+        }
+        signingKey = ed25519.PublicKey(decoded)
+    }
+
     return &Bot{
         api:          api,
         db:           database,
         jupiter:      jupiterClient,
         config:       cfg,
-        allowedUsers: make(map[int64]bool),
+        allowedUsers: allowedUsers,
+        signingKey:   signingKey,
+        logger:       logger.FromContext(context.Background()).Named("telegram"),
     }, nil
 }
 
+// SetStrategyEngine wires a strategy engine into the bot. Once set, a
+// successful /swap registers the new position with it via Enter instead
+// of leaving the sell entirely up to the user.
+func (b *Bot) SetStrategyEngine(engine *strategy.Engine) {
+    b.mutex.Lock()
+    b.strategy = engine
+    b.mutex.Unlock()
+}
+
+// setLogger swaps in l, guarded by mutex since Start and StartNotifier run
+// as independent goroutines and both adopt ctx's logger on entry.
+func (b *Bot) setLogger(l *logger.Logger) {
+    b.mutex.Lock()
+    b.logger = l
+    b.mutex.Unlock()
+}
+
+func (b *Bot) log() *logger.Logger {
+    b.mutex.RLock()
+    defer b.mutex.RUnlock()
+    return b.logger
+}
+
 func (b *Bot) Start(ctx context.Context) error {
+    b.setLogger(logger.FromContext(ctx).Named("telegram"))
+
     updateConfig := tgbotapi.NewUpdate(0)
     updateConfig.Timeout = 60
 
@@ -64,6 +127,50 @@ func (b *Bot) Start(ctx context.Context) error {
     }
 }
 
+// StartNotifier subscribes to pubsub.TopicPricesUpdated and
+// pubsub.TopicPLUpdated and pushes a message to NotifyChatID for each,
+// so the bot can alert a chat about price moves without the chat having
+// to poll with /price or /pl
+func (b *Bot) StartNotifier(ctx context.Context, bus pubsub.Subscriber) {
+    b.setLogger(logger.FromContext(ctx).Named("telegram"))
+
+    if b.config.NotifyChatID == 0 {
+        return
+    }
+
+    plEvents, unsubscribePL, err := bus.Subscribe(pubsub.TopicPLUpdated)
+    if err != nil {
+        b.log().Error("failed to subscribe to P&L updates", logger.F("error", err.Error()))
+        return
+    }
+    defer unsubscribePL()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case event, ok := <-plEvents:
+            if !ok {
+                return
+            }
+            pl, ok := event.Payload.(*models.TokenProfitLoss)
+            if !ok {
+                continue
+            }
+            b.notify(fmt.Sprintf("*%s*\nP/L: %.2f%% (%.8f SOL)", pl.Mint, pl.ProfitLossPct, pl.ProfitLoss))
+        }
+    }
+}
+
+// notify sends a message to the configured notification chat
+func (b *Bot) notify(text string) {
+    msg := tgbotapi.NewMessage(b.config.NotifyChatID, text)
+    msg.ParseMode = tgbotapi.ModeMarkdown
+    if _, err := b.api.Send(msg); err != nil {
+        b.log().Error("failed to send notification", logger.F("error", err.Error()))
+    }
+}
+
 func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
     // Split command and arguments
     parts := strings.Fields(message.Text)
@@ -74,6 +181,15 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
     command := strings.ToLower(parts[0])
     args := parts[1:]
 
+    if privilegedCommands[command] {
+        authorizedArgs, ok := b.authorize(message, command, args)
+        if !ok {
+            b.sendReply(message.Chat.ID, "Unauthorized: this command must come from an allow-listed chat or carry a valid signature")
+            return
+        }
+        args = authorizedArgs
+    }
+
     var reply string
     var err error
 
@@ -83,11 +199,13 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
                 "Available commands:\n" +
                 "/tokens - View tokens in queue\n" +
                 "/price <symbol> - Get current price for a token\n" +
+                "/oracle <symbol> - Get the Pyth oracle price for a token\n" +
                 "/pl - View profit/loss for all monitored tokens\n" +
                 "/config - View current configuration\n" +
                 "/setconfig <key> <value> - Update configuration\n" +
                 "/dryrun <mint> <amount> - Simulate a token swap\n" +
-                "/swap <mint> <amount> - Execute a real token swap"
+                "/swap <mint> <amount> - Execute a real token swap\n\n" +
+                "/setconfig, /dryrun, and /swap require either an allow-listed chat or a trailing ed25519 signature over the command"
 
     case "/tokens":
         reply, err = b.handleTokensCommand(ctx)
@@ -99,6 +217,13 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
             reply, err = b.handlePriceCommand(ctx, args[0])
         }
 
+    case "/oracle":
+        if len(args) < 1 {
+            reply = "Usage: /oracle <symbol>"
+        } else {
+            reply, err = b.handleOracleCommand(ctx, args[0])
+        }
+
     case "/pl":
         reply, err = b.handleProfitLossCommand(ctx)
 
@@ -131,15 +256,48 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
         reply = fmt.Sprintf("Error: %v", err)
     }
 
-    // Send the reply
-    msg := tgbotapi.NewMessage(message.Chat.ID, reply)
+    b.sendReply(message.Chat.ID, reply)
+}
+
+// sendReply sends a Markdown-formatted message back to chatID
+func (b *Bot) sendReply(chatID int64, text string) {
+    msg := tgbotapi.NewMessage(chatID, text)
     msg.ParseMode = tgbotapi.ModeMarkdown
-    
+
     if _, err := b.api.Send(msg); err != nil {
-        log.Printf("Error sending message: %v", err)
+        b.log().Error("failed to send message", logger.F("chat_id", chatID), logger.F("error", err.Error()))
     }
 }
 
+// authorize enforces that a privileged command either comes from an
+// allow-listed chat, or carries a valid ed25519 signature - over
+// "<command> <args...>", computed with the operator's SigningPrivateKey -
+// as its final argument, so a leaked Telegram bot token alone can't be
+// used to drain funds. It returns args with any trailing signature
+// stripped, ready to pass to the command's handler.
+func (b *Bot) authorize(message *tgbotapi.Message, command string, args []string) ([]string, bool) {
+    if b.allowedUsers[message.Chat.ID] || (message.From != nil && b.allowedUsers[message.From.ID]) {
+        return args, true
+    }
+
+    if b.signingKey == nil || len(args) == 0 {
+        return args, false
+    }
+
+    sig, err := base64.StdEncoding.DecodeString(args[len(args)-1])
+    if err != nil || len(sig) != ed25519.SignatureSize {
+        return args, false
+    }
+
+    payload := args[:len(args)-1]
+    signedText := strings.Join(append([]string{command}, payload...), " ")
+    if !ed25519.Verify(b.signingKey, []byte(signedText), sig) {
+        return args, false
+    }
+
+    return payload, true
+}
+
 func (b *Bot) handleTokensCommand(ctx context.Context) (string, error) {
     tokens, err := b.db.GetTokensInQueue(b.config.QueueSize)
     if err != nil {
@@ -188,6 +346,33 @@ func (b *Bot) handlePriceCommand(ctx context.Context, symbol string) (string, er
         latestPrice.Timestamp.Format(time.RFC822)), nil
 }
 
+// handleOracleCommand shows the latest Pyth oracle price for a token,
+// alongside its confidence band and staleness, so it can be compared
+// against the Jupiter route price before executing a swap
+func (b *Bot) handleOracleCommand(ctx context.Context, symbol string) (string, error) {
+    token, err := b.db.GetTokenBySymbol(symbol)
+    if err != nil {
+        return "", fmt.Errorf("token not found: %s", symbol)
+    }
+
+    oracle, err := b.db.GetOraclePrice(token.Mint)
+    if err != nil {
+        return "", fmt.Errorf("no Pyth oracle feed for %s", symbol)
+    }
+
+    staleness := time.Since(oracle.Timestamp)
+
+    return fmt.Sprintf(
+        "*%s (%s) — Pyth Oracle*\n"+
+            "Price: %.8f SOL\n"+
+            "Confidence: +/- %.8f SOL\n"+
+            "Publish Slot: %d\n"+
+            "Staleness: %s",
+        token.Name, token.Symbol,
+        oracle.Price, oracle.Confidence, oracle.PublishSlot,
+        staleness.Round(time.Second)), nil
+}
+
 func (b *Bot) handleProfitLossCommand(ctx context.Context) (string, error) {
     tokens, err := b.db.GetTokensInQueue(b.config.QueueSize)
     if err != nil {
@@ -258,6 +443,13 @@ func (b *Bot) handleSetConfigCommand(key, value string) (string, error) {
         return "", fmt.Errorf("unknown configuration key: %s", key)
     }
 
+    // Persist under the same lock that guarded the mutation above, so a
+    // concurrent /setconfig can't interleave a save with a half-updated
+    // config and so the change survives a restart.
+    if err := b.config.Save(""); err != nil {
+        b.log().Error("failed to persist config", logger.F("error", err.Error()))
+    }
+
     return fmt.Sprintf("Configuration updated: %s = %s", key, value), nil
 }
 
@@ -295,9 +487,22 @@ func (b *Bot) handleSwapCommand(ctx context.Context, mint string, amountStr stri
         return "", fmt.Errorf("invalid amount: %s", amountStr)
     }
 
+    quote, err := b.jupiter.GetQuote(ctx, mint)
+    if err != nil {
+        return "", fmt.Errorf("failed to price swap: %w", err)
+    }
+
     if err := b.jupiter.ExecuteSwap(ctx, mint, amount, b.config.UserPublicKey); err != nil {
         return "", fmt.Errorf("swap failed: %v", err)
     }
 
+    // Hand the new position to the strategy engine so it starts managing
+    // its exit; a manual /swap is as much an "entry" as an auto-buy would be.
+    if b.strategy != nil {
+        if err := b.strategy.Enter(mint, quote.Price, amount, time.Now()); err != nil {
+            b.log().Error("failed to register position with strategy engine", logger.F("mint", mint), logger.F("error", err.Error()))
+        }
+    }
+
     return fmt.Sprintf("Successfully executed swap of %.4f SOL for token %s", amount, mint), nil
 }
@@ -0,0 +1,58 @@
+package telegram
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AwaitRegistrations connects to the Telegram Bot API with token and
+// collects the chat ID of every distinct chat that sends "/register code"
+// until ctx is canceled. It's used by `sniper gen` to build the initial
+// AllowedUsers list: the code is printed to the operator's terminal, so
+// registering a chat proves whoever controls it also controls the host
+// `gen` is running on.
+func AwaitRegistrations(ctx context.Context, token, code string) ([]int64, error) {
+    api, err := tgbotapi.NewBotAPI(token)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
+    }
+
+    log := logger.FromContext(ctx).Named("telegram")
+
+    updateConfig := tgbotapi.NewUpdate(0)
+    updateConfig.Timeout = 30
+    updates := api.GetUpdatesChan(updateConfig)
+    defer api.StopReceivingUpdates()
+
+    want := "/register " + code
+    seen := make(map[int64]bool)
+    var allowed []int64
+
+    for {
+        select {
+        case <-ctx.Done():
+            return allowed, nil
+        case update := <-updates:
+            if update.Message == nil || strings.TrimSpace(update.Message.Text) != want {
+                continue
+            }
+
+            chatID := update.Message.Chat.ID
+            if seen[chatID] {
+                continue
+            }
+            seen[chatID] = true
+            allowed = append(allowed, chatID)
+            log.Info("registered chat", logger.F("chat_id", chatID))
+
+            reply := tgbotapi.NewMessage(chatID, "Registered. This chat can now run privileged commands.")
+            if _, err := api.Send(reply); err != nil {
+                log.Error("failed to send registration confirmation", logger.F("chat_id", chatID), logger.F("error", err.Error()))
+            }
+        }
+    }
+}
@@ -3,15 +3,47 @@ package websocket
 import (
     "context"
     "encoding/json"
-    "log"
+    "fmt"
+    "math/rand"
     "sync"
     "time"
 
     "github.com/gorilla/websocket"
     "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/logger"
     "github.com/sial-ari/solana-token-sniper/internal/models"
+    "github.com/sial-ari/solana-token-sniper/internal/pubsub"
+    "github.com/sial-ari/solana-token-sniper/internal/pyth"
 )
 
+// Reconnect tuning: full-jitter exponential backoff between 1s and 60s,
+// giving up after maxReconnectAttempts in a row. pingInterval/pongWait
+// detect a silently-dead socket that ReadMessage would otherwise block on
+// forever.
+const (
+    reconnectBaseDelay   = time.Second
+    reconnectMaxDelay    = 60 * time.Second
+    maxReconnectAttempts = 10
+    pingInterval         = 30 * time.Second
+    pongWait             = 45 * time.Second
+)
+
+// subscription is one pumpportal.fun subscribe message, persisted so it
+// can be re-sent after every reconnect instead of only at the initial
+// connect.
+type subscription struct {
+    method string
+    key    string // mint or wallet address; unused for subscribeNewToken
+}
+
+func (s subscription) payload() map[string]interface{} {
+    p := map[string]interface{}{"method": s.method}
+    if s.key != "" {
+        p["keys"] = []string{s.key}
+    }
+    return p
+}
+
 // Client manages the WebSocket connection and token processing
 type Client struct {
     conn          *websocket.Conn
@@ -21,7 +53,38 @@ type Client struct {
     mutex         sync.Mutex
     isConnected   bool
     done          chan struct{}
-    reconnectWait time.Duration
+
+    subscriptions     []subscription // resent on every reconnect
+    reconnectAttempts int
+    circuitOpen       bool
+    circuitCh         chan error // receives an error the one time the circuit trips
+    pingDone          chan struct{}
+
+    // pythClient and pythFeeds are optional: when a newly-minted token's
+    // mint has a known Pyth price account, we subscribe so it gets
+    // authoritative oracle prices instead of relying on Jupiter quotes alone
+    pythClient *pyth.Client
+    pythFeeds  map[string]string
+
+    // bus is optional: when set, every persisted NewToken is also
+    // published to pubsub.TopicNewTokens so consumers (price monitors,
+    // notifiers, external sinks) can react without polling the database
+    bus pubsub.Publisher
+
+    // frameRecorder is optional: when set, every raw message read off the
+    // connection is captured into a testvectors corpus before decoding
+    frameRecorder frameRecorder
+
+    // logger defaults to a no-op and is upgraded to the context-carried
+    // logger on Connect, so every line it emits carries a mint= field
+    logger *logger.Logger
+}
+
+// frameRecorder captures a raw frame for later replay. It's satisfied by
+// *testvectors.Recorder; defined locally to avoid an import cycle, since
+// testvectors replays back into this package.
+type frameRecorder interface {
+    Record(raw []byte) error
 }
 
 // NewClient creates a new WebSocket client with the specified configuration
@@ -31,49 +94,215 @@ func NewClient(url string, database *db.Database, queueSize int) *Client {
         db:            database,
         queueSize:     queueSize,
         done:          make(chan struct{}),
-        reconnectWait: 5 * time.Second,
+        logger:        logger.FromContext(context.Background()).Named("websocket"),
+        subscriptions: []subscription{
+            {method: "subscribeNewToken"},
+        },
+        circuitCh: make(chan error, 1),
+    }
+}
+
+// SetTraderWallet registers the trader's own wallet for
+// subscribeAccountTrade, so fills on it show up without a restart. Sent
+// immediately if already connected; always re-sent on reconnect.
+func (c *Client) SetTraderWallet(pubKey string) {
+    c.addSubscription(subscription{method: "subscribeAccountTrade", key: pubKey})
+}
+
+// TrackPosition adds mint to subscribeTokenTrade, so trades against an
+// open position are observed without a restart. Call it right after
+// strategy.Engine.Enter opens one.
+func (c *Client) TrackPosition(mint string) {
+    c.addSubscription(subscription{method: "subscribeTokenTrade", key: mint})
+}
+
+// UntrackPosition removes mint's subscribeTokenTrade subscription once its
+// position is fully closed.
+func (c *Client) UntrackPosition(mint string) {
+    c.mutex.Lock()
+    for i, sub := range c.subscriptions {
+        if sub.method == "subscribeTokenTrade" && sub.key == mint {
+            c.subscriptions = append(c.subscriptions[:i], c.subscriptions[i+1:]...)
+            break
+        }
+    }
+    conn := c.conn
+    c.mutex.Unlock()
+
+    if conn == nil {
+        return
     }
+    payload := map[string]interface{}{"method": "unsubscribeTokenTrade", "keys": []string{mint}}
+    message, err := json.Marshal(payload)
+    if err != nil {
+        return
+    }
+
+    c.mutex.Lock()
+    conn.WriteMessage(websocket.TextMessage, message)
+    c.mutex.Unlock()
+}
+
+// addSubscription persists sub so it's re-sent on every future reconnect,
+// and sends it immediately if a connection is already live.
+func (c *Client) addSubscription(sub subscription) {
+    c.mutex.Lock()
+    for _, existing := range c.subscriptions {
+        if existing.method == sub.method && existing.key == sub.key {
+            c.mutex.Unlock()
+            return
+        }
+    }
+    c.subscriptions = append(c.subscriptions, sub)
+    conn := c.conn
+    c.mutex.Unlock()
+
+    if conn == nil {
+        return
+    }
+    message, err := json.Marshal(sub.payload())
+    if err != nil {
+        return
+    }
+
+    c.mutex.Lock()
+    conn.WriteMessage(websocket.TextMessage, message)
+    c.mutex.Unlock()
+}
+
+// SetPythClient wires an already-connected Pyth client into the token
+// pipeline, along with a static mint -> price account mapping for the
+// tokens that actually have a Pyth feed
+func (c *Client) SetPythClient(p *pyth.Client, feeds map[string]string) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    c.pythClient = p
+    c.pythFeeds = feeds
+}
+
+// SetFrameRecorder wires a testvectors recorder into the client so every
+// raw message is captured before it's decoded
+func (c *Client) SetFrameRecorder(r frameRecorder) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    c.frameRecorder = r
+}
+
+// recordFrame captures a raw message when a frame recorder is configured
+func (c *Client) recordFrame(message []byte) {
+    c.mutex.Lock()
+    recorder := c.frameRecorder
+    c.mutex.Unlock()
+
+    if recorder == nil {
+        return
+    }
+    if err := recorder.Record(message); err != nil {
+        c.logger.Error("failed to record frame", logger.F("error", err))
+    }
+}
+
+// SetBus wires a pubsub bus into the client so newly-seen tokens are
+// published to pubsub.TopicNewTokens as well as saved to the database
+func (c *Client) SetBus(bus pubsub.Publisher) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    c.bus = bus
 }
 
 // Connect establishes the WebSocket connection and handles reconnection
 func (c *Client) Connect(ctx context.Context) error {
+    c.logger = logger.FromContext(ctx).Named("websocket")
+
     dialer := websocket.DefaultDialer
     conn, _, err := dialer.DialContext(ctx, c.url, nil)
     if err != nil {
         return err
     }
 
+    conn.SetReadDeadline(time.Now().Add(pongWait))
+    conn.SetPongHandler(func(string) error {
+        return conn.SetReadDeadline(time.Now().Add(pongWait))
+    })
+
     c.mutex.Lock()
     c.conn = conn
     c.isConnected = true
+    c.reconnectAttempts = 0
     c.mutex.Unlock()
 
-    // Subscribe to token creation events
-    if err := c.subscribeToTokenCreation(); err != nil {
+    // Re-send every persisted subscription - new token creation plus
+    // subscribeTokenTrade/subscribeAccountTrade for whatever positions and
+    // wallet were tracked before the drop - instead of only subscribing
+    // to new tokens on this first connect.
+    if err := c.resubscribeAll(); err != nil {
+        conn.Close()
         return err
     }
 
+    pingDone := make(chan struct{})
+    c.mutex.Lock()
+    c.pingDone = pingDone
+    c.mutex.Unlock()
+    go c.heartbeat(ctx, conn, pingDone)
+
     // Start message handling in a separate goroutine
     go c.handleMessages(ctx)
 
     return nil
 }
 
-// subscribeToTokenCreation sends the subscription message to the server
-func (c *Client) subscribeToTokenCreation() error {
-    payload := map[string]interface{}{
-        "method": "subscribeNewToken",
-    }
-    
-    message, err := json.Marshal(payload)
-    if err != nil {
-        return err
+// resubscribeAll re-sends every subscription in c.subscriptions, so a
+// fresh connection picks back up where the dropped one left off.
+func (c *Client) resubscribeAll() error {
+    c.mutex.Lock()
+    subs := make([]subscription, len(c.subscriptions))
+    copy(subs, c.subscriptions)
+    conn := c.conn
+    c.mutex.Unlock()
+
+    for _, sub := range subs {
+        message, err := json.Marshal(sub.payload())
+        if err != nil {
+            return fmt.Errorf("failed to marshal subscription payload: %w", err)
+        }
+
+        c.mutex.Lock()
+        err = conn.WriteMessage(websocket.TextMessage, message)
+        c.mutex.Unlock()
+        if err != nil {
+            return fmt.Errorf("failed to send subscription message: %w", err)
+        }
     }
 
-    c.mutex.Lock()
-    defer c.mutex.Unlock()
-    
-    return c.conn.WriteMessage(websocket.TextMessage, message)
+    c.logger.Info("resubscribed", logger.F("count", len(subs)))
+    return nil
+}
+
+// heartbeat pings the connection every pingInterval; a failed write means
+// the socket is dead, so it's closed to unblock ReadMessage in
+// handleMessages rather than waiting on pongWait. It exits once done is
+// closed (by Close or the next successful connect) or the write fails.
+func (c *Client) heartbeat(ctx context.Context, conn *websocket.Conn, done chan struct{}) {
+    ticker := time.NewTicker(pingInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-done:
+            return
+        case <-ctx.Done():
+            return
+        case <-c.done:
+            return
+        case <-ticker.C:
+            if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+                c.logger.Error("heartbeat ping failed, closing connection", logger.F("error", err))
+                conn.Close()
+                return
+            }
+        }
+    }
 }
 
 // handleMessages processes incoming WebSocket messages
@@ -87,19 +316,28 @@ func (c *Client) handleMessages(ctx context.Context) {
         default:
             _, message, err := c.conn.ReadMessage()
             if err != nil {
-                log.Printf("Error reading message: %v", err)
+                c.logger.Error("failed to read message", logger.F("error", err))
                 c.handleDisconnect(ctx)
                 return
             }
 
+            c.recordFrame(message)
+
             if err := c.processMessage(message); err != nil {
-                log.Printf("Error processing message: %v", err)
+                c.logger.Error("failed to process message", logger.F("error", err))
                 continue
             }
         }
     }
 }
 
+// ProcessRawMessage runs a single raw message through the same decoding
+// path as a live connection would. It's exported for replaying a
+// testvectors corpus against the client without a real WebSocket.
+func (c *Client) ProcessRawMessage(message []byte) error {
+    return c.processMessage(message)
+}
+
 // processMessage handles an individual WebSocket message
 func (c *Client) processMessage(message []byte) error {
     var token models.NewToken
@@ -107,6 +345,11 @@ func (c *Client) processMessage(message []byte) error {
         return err
     }
 
+    tokenLogger := c.logger.With(
+        logger.F("mint", token.Mint),
+        logger.F("signature", token.Signature),
+    )
+
     // Set creation timestamp
     token.CreatedAt = time.Now()
 
@@ -140,11 +383,49 @@ func (c *Client) processMessage(message []byte) error {
         return err
     }
 
-    log.Printf("Processed new token: %s (%s)", token.Name, token.Mint)
+    c.maybeSubscribePythFeed(tokenLogger, token.Mint)
+    c.publishNewToken(tokenLogger, &token)
+
+    tokenLogger.Info("processed new token", logger.F("name", token.Name))
     return nil
 }
 
-// handleDisconnect manages connection loss and reconnection attempts
+// publishNewToken fans the token out to pubsub.TopicNewTokens subscribers
+func (c *Client) publishNewToken(log *logger.Logger, token *models.NewToken) {
+    c.mutex.Lock()
+    bus := c.bus
+    c.mutex.Unlock()
+
+    if bus == nil {
+        return
+    }
+
+    if err := bus.Publish(pubsub.TopicNewTokens, token); err != nil {
+        log.Error("failed to publish new token event", logger.F("error", err))
+    }
+}
+
+// maybeSubscribePythFeed subscribes the token's mint to its Pyth price
+// account when one is known, so it starts receiving oracle price updates
+func (c *Client) maybeSubscribePythFeed(log *logger.Logger, mint string) {
+    c.mutex.Lock()
+    pythClient := c.pythClient
+    priceAccount, hasFeed := c.pythFeeds[mint]
+    c.mutex.Unlock()
+
+    if pythClient == nil || !hasFeed {
+        return
+    }
+
+    if err := pythClient.SubscribePriceAccount(mint, priceAccount); err != nil {
+        log.Error("failed to subscribe to Pyth feed", logger.F("error", err))
+    }
+}
+
+// handleDisconnect manages connection loss and reconnection attempts:
+// full-jitter exponential backoff starting at reconnectBaseDelay and
+// capped at reconnectMaxDelay, tripping the circuit breaker instead of
+// retrying forever once maxReconnectAttempts is exceeded.
 func (c *Client) handleDisconnect(ctx context.Context) {
     c.mutex.Lock()
     if c.conn != nil {
@@ -153,26 +434,96 @@ func (c *Client) handleDisconnect(ctx context.Context) {
     c.isConnected = false
     c.mutex.Unlock()
 
-    // Attempt to reconnect
-    ticker := time.NewTicker(c.reconnectWait)
-    defer ticker.Stop()
-
     for {
+        if c.circuitTripped() {
+            return
+        }
+
+        c.mutex.Lock()
+        c.reconnectAttempts++
+        attempt := c.reconnectAttempts
+        c.mutex.Unlock()
+
+        if attempt > maxReconnectAttempts {
+            c.tripCircuit(attempt - 1)
+            return
+        }
+
+        delay := backoffDelay(attempt, reconnectBaseDelay, reconnectMaxDelay)
+        c.logger.Warn("websocket disconnected, reconnecting",
+            logger.F("attempt", attempt),
+            logger.F("delay_ms", delay.Milliseconds()),
+        )
+
         select {
         case <-ctx.Done():
             return
         case <-c.done:
             return
-        case <-ticker.C:
-            if err := c.Connect(ctx); err != nil {
-                log.Printf("Reconnection failed: %v", err)
-                continue
-            }
-            return
+        case <-time.After(delay):
+        }
+
+        if err := c.Connect(ctx); err != nil {
+            c.logger.Error("reconnection failed", logger.F("attempt", attempt), logger.F("error", err))
+            continue
         }
+        return
     }
 }
 
+// backoffDelay returns a full-jitter exponential backoff for the given
+// attempt (1-indexed): a uniformly random duration between 0 and
+// min(max, base*2^(attempt-1)).
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+    cap := base * time.Duration(uint64(1)<<uint(attempt-1))
+    if cap <= 0 || cap > max {
+        cap = max
+    }
+    return time.Duration(rand.Int63n(int64(cap)))
+}
+
+// circuitTripped reports whether handleDisconnect has given up;
+// handleDisconnect checks it before every reconnect attempt so a tripped
+// breaker stops retrying instead of looping forever against a dead feed.
+func (c *Client) circuitTripped() bool {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+    return c.circuitOpen
+}
+
+// tripCircuit marks the breaker open and surfaces an error on
+// CircuitBreakerTripped so the caller can alarm on connection instability
+// instead of only seeing it in logs.
+func (c *Client) tripCircuit(attempts int) {
+    c.mutex.Lock()
+    c.circuitOpen = true
+    c.mutex.Unlock()
+
+    c.logger.Error("websocket circuit breaker tripped, giving up on reconnect", logger.F("attempts", attempts))
+    select {
+    case c.circuitCh <- fmt.Errorf("websocket: giving up after %d reconnect attempts", attempts):
+    default:
+    }
+}
+
+// CircuitBreakerTripped returns a channel that receives one error the
+// first time reconnects are exhausted, so the caller can alarm on
+// connection instability during a token launch surge instead of only
+// seeing it in logs.
+func (c *Client) CircuitBreakerTripped() <-chan error {
+    return c.circuitCh
+}
+
+// ResetCircuitBreaker clears a tripped breaker and zeroes the attempt
+// counter, so an operator can resume the client after addressing whatever
+// took the feed down.
+func (c *Client) ResetCircuitBreaker() {
+    c.mutex.Lock()
+    c.circuitOpen = false
+    c.reconnectAttempts = 0
+    c.mutex.Unlock()
+}
+
 // Close gracefully shuts down the WebSocket connection
 func (c *Client) Close() error {
     c.mutex.Lock()
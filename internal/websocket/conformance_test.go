@@ -0,0 +1,66 @@
+package websocket
+
+import (
+    "testing"
+
+    "github.com/sial-ari/solana-token-sniper/internal/db"
+    "github.com/sial-ari/solana-token-sniper/internal/websocket/testvectors"
+)
+
+// TestConformance replays the pathological test-vector corpus against a
+// fresh in-memory database and asserts the resulting state is exactly what
+// processMessage decoding should produce: partial JSON is rejected,
+// duplicate mints are rejected by the tokens table's primary key, and the
+// first insert for a mint wins regardless of message ordering.
+func TestConformance(t *testing.T) {
+    database, err := db.Initialize(":memory:")
+    if err != nil {
+        t.Fatalf("failed to initialize in-memory database: %v", err)
+    }
+
+    client := NewClient("", database, 5)
+
+    errs, err := testvectors.Replay("testvectors/testdata/pathological.jsonl", client.ProcessRawMessage)
+    if err != nil {
+        t.Fatalf("failed to replay corpus: %v", err)
+    }
+
+    // Frame 1 (truncated JSON), frame 2 (duplicate MintA) and frame 4
+    // (duplicate MintC) are expected to fail; frames 0 and 3 succeed
+    if len(errs) != 3 {
+        t.Fatalf("expected 3 replay errors, got %d: %+v", len(errs), errs)
+    }
+
+    tokens, err := database.GetTokensInQueue(10)
+    if err != nil {
+        t.Fatalf("failed to query tokens: %v", err)
+    }
+    if len(tokens) != 2 {
+        t.Fatalf("expected 2 tokens to have been saved, got %d", len(tokens))
+    }
+
+    byMint := make(map[string]string)
+    for _, tok := range tokens {
+        byMint[tok.Mint] = tok.Name
+    }
+
+    if name := byMint["MintA111111111111111111111111111111111111"]; name != "Token A" {
+        t.Errorf("expected MintA's first insert to win, got name %q", name)
+    }
+    if name := byMint["MintC333333333333333333333333333333333333"]; name != "Token C" {
+        t.Errorf("expected MintC's out-of-order sell event to win the insert, got name %q", name)
+    }
+
+    for _, mint := range []string{
+        "MintA111111111111111111111111111111111111",
+        "MintC333333333333333333333333333333333333",
+    } {
+        prices, err := database.GetPriceHistory(mint)
+        if err != nil {
+            t.Fatalf("failed to get price history for %s: %v", mint, err)
+        }
+        if len(prices) != 1 {
+            t.Errorf("expected exactly 1 price point for %s, got %d", mint, len(prices))
+        }
+    }
+}
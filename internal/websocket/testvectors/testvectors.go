@@ -0,0 +1,114 @@
+// Package testvectors records and replays raw pumpportal WebSocket frames
+// so regressions in message decoding can be caught without a live feed.
+// A corpus is a versioned JSONL file, one Frame per line.
+package testvectors
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "time"
+)
+
+// CorpusVersion is bumped whenever the Frame schema changes incompatibly
+const CorpusVersion = 1
+
+// Frame is a single captured WebSocket message, along with the wall-clock
+// time it was received so replay can (optionally) reproduce timing. Raw is
+// stored as a string rather than json.RawMessage so pathological frames
+// (partial JSON, truncated payloads) can be captured and replayed verbatim.
+type Frame struct {
+    Version   int       `json:"version"`
+    Timestamp time.Time `json:"timestamp"`
+    Raw       string    `json:"raw"`
+}
+
+// Recorder appends frames to a corpus file as JSONL
+type Recorder struct {
+    w io.Writer
+}
+
+// NewRecorder creates a Recorder that appends to the file at path,
+// creating it if it doesn't exist
+func NewRecorder(path string) (*Recorder, io.Closer, error) {
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to open corpus file: %w", err)
+    }
+    return &Recorder{w: f}, f, nil
+}
+
+// Record appends a single frame capturing the raw message as received
+func (r *Recorder) Record(raw []byte) error {
+    frame := Frame{
+        Version:   CorpusVersion,
+        Timestamp: time.Now(),
+        Raw:       string(raw),
+    }
+
+    data, err := json.Marshal(frame)
+    if err != nil {
+        return fmt.Errorf("failed to marshal frame: %w", err)
+    }
+
+    _, err = fmt.Fprintf(r.w, "%s\n", data)
+    return err
+}
+
+// LoadCorpus reads every frame from the JSONL corpus at path, in order
+func LoadCorpus(path string) ([]Frame, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open corpus file: %w", err)
+    }
+    defer f.Close()
+
+    var frames []Frame
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+    for scanner.Scan() {
+        line := scanner.Bytes()
+        if len(line) == 0 {
+            continue
+        }
+
+        var frame Frame
+        if err := json.Unmarshal(line, &frame); err != nil {
+            return nil, fmt.Errorf("failed to unmarshal frame: %w", err)
+        }
+        frames = append(frames, frame)
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read corpus file: %w", err)
+    }
+
+    return frames, nil
+}
+
+// Replay feeds every frame in the corpus at path into handler, in order.
+// handler's error is logged by the caller via the returned slice of
+// (index, error) pairs rather than aborting the replay, so a single
+// pathological frame doesn't prevent the rest of the corpus from running.
+type ReplayError struct {
+    Index int
+    Err   error
+}
+
+func Replay(path string, handler func(raw []byte) error) ([]ReplayError, error) {
+    frames, err := LoadCorpus(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var errs []ReplayError
+    for i, frame := range frames {
+        if err := handler([]byte(frame.Raw)); err != nil {
+            errs = append(errs, ReplayError{Index: i, Err: err})
+        }
+    }
+
+    return errs, nil
+}